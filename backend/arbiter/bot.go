@@ -0,0 +1,176 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/word"
+)
+
+// How many moves ahead the hard bot searches
+const hardBotSearchDepth = 4
+
+// Score for a position the player to move has already lost, ahead of any mobility score
+const botLossScore = -1000
+
+// processBotTurns plays the bot's move in every game where it has had time to think.
+// Turns are claimed atomically, so only one arbiter plays each move
+func (a *Arbiter) processBotTurns() {
+	gameIDs, err := a.redisClient.AtomicClaimBotTurns(batchSize)
+	if err != nil {
+		log.Printf("Error claiming bot turns: %v", err)
+		return
+	}
+
+	for _, gameID := range gameIDs {
+		a.playBotTurn(gameID)
+	}
+}
+
+// playBotTurn plays the bot's move in a game it has claimed.
+// Moves go through AtomicSubmitWord like a player's, so clocks, forfeits and events work unchanged
+func (a *Arbiter) playBotTurn(gameID string) {
+	game, err := a.redisClient.GetGame(gameID)
+	if err != nil {
+		log.Printf("Bot failed to get game %s: %v", gameID, err)
+		return
+	}
+	bot, ok := entities.GetBotByID(game.CurrentTurnID)
+	if game.Status != entities.GameStatusActive || !ok {
+		// The game has ended or gone, there is nothing left to play
+		if err := a.redisClient.RemoveBotTurn(gameID); err != nil {
+			log.Printf("Error removing bot turn for game %s: %v", gameID, err)
+		}
+		return
+	}
+
+	played, err := a.playedWords(game)
+	if err != nil {
+		log.Printf("Bot failed to get played words for game %s: %v", gameID, err)
+		return
+	}
+
+	move := chooseBotMove(a.wordService, bot.Level, game.CurrentWord, played)
+	if move == "" {
+		// The bot is stuck and loses
+		a.endGameIfNoMovesLeft(game, game.CurrentWord, bot.ID)
+		return
+	}
+
+	_, newWord, nextTurnID, err := a.redisClient.AtomicSubmitWord(gameID, bot.ID, bot.Name, move)
+	if err != nil {
+		log.Printf("Bot failed to submit word in game %s: %v", gameID, err)
+		return
+	}
+
+	log.Printf("Bot %s submitted word '%s' for game %s", bot.Name, newWord, gameID)
+
+	a.endGameIfNoMovesLeft(game, newWord, nextTurnID)
+}
+
+// playedWords returns the words that can't be played again in a game, none if it allows repeats
+func (a *Arbiter) playedWords(game entities.Game) (map[string]bool, error) {
+	played := make(map[string]bool)
+	if game.AllowRepeats {
+		return played, nil
+	}
+
+	playedWords, err := a.redisClient.GetPlayedWords(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, playedWord := range playedWords {
+		played[playedWord] = true
+	}
+	return played, nil
+}
+
+// endGameIfNoMovesLeft ends the game when the player to move from currentWord has no legal word left
+func (a *Arbiter) endGameIfNoMovesLeft(game entities.Game, currentWord string, nextTurnID string) {
+	played, err := a.playedWords(game)
+	if err != nil {
+		log.Printf("Error getting played words: %v", err)
+		return
+	}
+	if len(availableMoves(a.wordService, currentWord, played)) > 0 {
+		return
+	}
+
+	winnerID, err := a.redisClient.AtomicEndGameNoMoves(game.ID, nextTurnID, currentWord)
+	if err != nil {
+		log.Printf("Error ending game %s with no moves left: %v", game.ID, err)
+		return
+	}
+
+	log.Printf("Player %s has no moves left in game %s, winner: %s", nextTurnID, game.ID, winnerID)
+}
+
+// chooseBotMove picks the bot's next word from currentWord.
+// Easy bots play any legal word, medium bots leave the opponent as few replies as possible
+// and hard bots search several moves ahead. Returns "" if there is no legal word
+func chooseBotMove(words *word.Service, level entities.Difficulty, currentWord string, played map[string]bool) string {
+	moves := availableMoves(words, currentWord, played)
+	if len(moves) == 0 {
+		return ""
+	}
+
+	depth := 0
+	switch level {
+	case entities.DifficultyEasy:
+		return moves[rand.Intn(len(moves))]
+	case entities.DifficultyMedium:
+		depth = 1
+	default:
+		depth = hardBotSearchDepth
+	}
+
+	var best []string
+	bestScore := 0
+	for _, move := range moves {
+		played[move] = true
+		score := -negamax(words, move, played, depth-1)
+		delete(played, move)
+
+		if len(best) == 0 || score > bestScore {
+			best = []string{move}
+			bestScore = score
+		} else if score == bestScore {
+			best = append(best, move)
+		}
+	}
+
+	return best[rand.Intn(len(best))]
+}
+
+// negamax scores currentWord for the player about to move from it.
+// A player with no legal word has lost, otherwise leaf positions are scored by how many words they can play
+func negamax(words *word.Service, currentWord string, played map[string]bool, depth int) int {
+	moves := availableMoves(words, currentWord, played)
+	if len(moves) == 0 {
+		// Losing sooner is worse than losing later
+		return botLossScore - depth
+	}
+	if depth == 0 {
+		return len(moves)
+	}
+
+	best := botLossScore - hardBotSearchDepth - 1
+	for _, move := range moves {
+		played[move] = true
+		best = max(best, -negamax(words, move, played, depth-1))
+		delete(played, move)
+	}
+	return best
+}
+
+// availableMoves returns the words one letter change from currentWord that haven't been played
+func availableMoves(words *word.Service, currentWord string, played map[string]bool) []string {
+	moves := make([]string, 0)
+	for _, move := range words.Moves(currentWord) {
+		if !played[move] {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
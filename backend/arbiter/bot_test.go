@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/redisclient"
+	"github.com/simonPacker7/Delta/backend/shared/word"
+)
+
+// From COLD, playing BOLD leaves the opponent stuck (its only neighbour COLD is played),
+// while CORD leaves them WORD
+func TestBotPlaysWinningMove(t *testing.T) {
+	words := word.NewTestService()
+
+	for _, level := range []entities.Difficulty{entities.DifficultyMedium, entities.DifficultyHard} {
+		move := chooseBotMove(words, level, "COLD", map[string]bool{"COLD": true})
+		if move != "BOLD" {
+			t.Fatalf("expected %s bot to play BOLD, got %s", level, move)
+		}
+	}
+
+	if move := chooseBotMove(words, entities.DifficultyHard, "BOLD", map[string]bool{"COLD": true, "BOLD": true}); move != "" {
+		t.Fatalf("expected no move from a dead end, got %s", move)
+	}
+}
+
+// A bot moving first in a rematch is played by whichever arbiter claims its turn,
+// with no game-service instance following the game
+func TestBotTurnClaimedOnce(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := redisclient.NewRedisClient(redisclient.RedisConfig{Addr: mr.Addr()})
+	arbiter := NewArbiter(redisClient, nil, word.NewTestService(), time.Minute, time.Minute)
+
+	bot, _ := entities.GetBotByLevel(entities.DifficultyMedium)
+	gameID := redisclient.GenerateId()
+	err := redisClient.CreateReadyGame(entities.Game{
+		ID:             gameID,
+		Type:           entities.GameTypeBot,
+		Status:         entities.GameStatusReady,
+		Player1ID:      bot.ID,
+		Player1Name:    bot.Name,
+		Player2ID:      "player-1",
+		Player2Name:    "Player 1",
+		CurrentWord:    "COLD",
+		CurrentTurnID:  bot.ID,
+		ConnectedCount: 1,
+		CreatedAt:      time.Now().UnixMilli(),
+		BotLevel:       bot.Level,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The player connects, starting the game with the bot's turn already past its think time
+	mr.SetTime(time.Now().Add(-time.Minute))
+	if _, started, _, err := redisClient.AtomicJoinGameSession(gameID); err != nil || !started {
+		t.Fatalf("expected the game to start, got %v (started: %v)", err, started)
+	}
+
+	claimed, err := redisClient.AtomicClaimBotTurns(batchSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 || claimed[0] != gameID {
+		t.Fatalf("expected to claim the bot turn in %s, got %v", gameID, claimed)
+	}
+	if again, _ := redisClient.AtomicClaimBotTurns(batchSize); len(again) != 0 {
+		t.Fatalf("expected a claimed turn not to be claimed again, got %v", again)
+	}
+
+	arbiter.playBotTurn(gameID)
+
+	game, err := redisClient.GetGame(gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Status != entities.GameStatusEnded || game.WinnerID != bot.ID || game.CurrentWord != "BOLD" {
+		t.Fatalf("expected the bot to win by playing BOLD, got %s at %s won by %q", game.Status, game.CurrentWord, game.WinnerID)
+	}
+	if mr.Exists("game:bot:turns") {
+		t.Fatal("expected no bot turn left queued")
+	}
+}
@@ -2,16 +2,33 @@ module github.com/simonPacker7/Delta/backend/arbiter
 
 go 1.25.4
 
-require github.com/simonPacker7/Delta/backend/shared/redisclient v0.0.0
+require (
+	github.com/alicebob/miniredis/v2 v2.39.0
+	github.com/simonPacker7/Delta/backend/shared/entities v0.0.0
+	github.com/simonPacker7/Delta/backend/shared/postgresclient v0.0.0
+	github.com/simonPacker7/Delta/backend/shared/redisclient v0.0.0
+)
 
 require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/google/uuid v1.6.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/pgx/v5 v5.7.6 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/redis/go-redis/v9 v9.17.2 // indirect
-	github.com/simonPacker7/Delta/backend/shared/entities v0.0.0 // indirect
+	github.com/simonPacker7/Delta/backend/shared/word v0.0.0
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	golang.org/x/crypto v0.37.0 // indirect
+	golang.org/x/sync v0.13.0 // indirect
+	golang.org/x/text v0.24.0 // indirect
 )
 
 replace github.com/simonPacker7/Delta/backend/shared/redisclient => ../shared/redisclient
 
 replace github.com/simonPacker7/Delta/backend/shared/entities => ../shared/entities
+
+replace github.com/simonPacker7/Delta/backend/shared/postgresclient => ../shared/postgresclient
+
+replace github.com/simonPacker7/Delta/backend/shared/word => ../shared/word
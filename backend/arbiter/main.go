@@ -7,12 +7,27 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/postgresclient"
 	"github.com/simonPacker7/Delta/backend/shared/redisclient"
+	"github.com/simonPacker7/Delta/backend/shared/word"
 )
 
 const (
-	pollInterval     = 2 * time.Second
-	batchSize        = 10
+	pollInterval = 2 * time.Second
+	batchSize    = 10
+
+	// Bot turns are checked more often than the other work, so bots answer soon after they have thought
+	botTurnPollInterval = 250 * time.Millisecond
+
+	// Waiting games rematched per poll, longest waiting first
+	matchmakingBatchSize = 50
+
+	// Default for how long a game can wait for an opponent before it is removed
+	defaultWaitingGameMaxAge = 10 * time.Minute
+
+	// Default for how long an online game waits for a human opponent before a bot takes the seat
+	defaultBotBackfillAfter = 30 * time.Second
 )
 
 func main() {
@@ -26,17 +41,61 @@ func main() {
 		redisURL = "localhost:6379"
 	}
 
+	dbURL := os.Getenv("DATABASE_URL")
+	dbName := os.Getenv("DATABASE_NAME")
+	dbUsername := os.Getenv("DATABASE_USERNAME")
+	dbPassword := os.Getenv("DATABASE_PASSWORD")
+
+	waitingGameMaxAge := defaultWaitingGameMaxAge
+	if maxAge := os.Getenv("WAITING_GAME_MAX_AGE"); maxAge != "" {
+		parsed, err := time.ParseDuration(maxAge)
+		if err != nil {
+			log.Fatalf("Invalid WAITING_GAME_MAX_AGE: %v", err)
+		}
+		waitingGameMaxAge = parsed
+	}
+
+	// Zero disables bot backfill
+	botBackfillAfter := defaultBotBackfillAfter
+	if backfillAfter := os.Getenv("BOT_BACKFILL_AFTER"); backfillAfter != "" {
+		parsed, err := time.ParseDuration(backfillAfter)
+		if err != nil {
+			log.Fatalf("Invalid BOT_BACKFILL_AFTER: %v", err)
+		}
+		botBackfillAfter = parsed
+	}
+
+	wordAssetsDir := os.Getenv("WORD_ASSETS_DIR")
+	if wordAssetsDir == "" {
+		wordAssetsDir = "/app/assets"
+	}
+
 	redisConfig := redisclient.RedisConfig{
 		Addr:     redisURL,
 		Password: redisPassword,
 		DB:       0,
 	}
 
+	postgresConfig := postgresclient.PostgresConfig{
+		Addr:     dbURL,
+		DB:       dbName,
+		Username: dbUsername,
+		Password: dbPassword,
+	}
+
 	rClient := redisclient.NewRedisClient(redisConfig)
 	log.Println("Connected to Redis")
 
+	pClient, err := postgresclient.NewPostgresClient(postgresConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to Postgres: %v", err)
+	}
+
+	// Start words for games backfilled with a bot, and the moves bots play
+	words := word.NewService(wordAssetsDir)
+
 	// Create arbiter
-	arbiter := NewArbiter(rClient)
+	arbiter := NewArbiter(rClient, pClient, words, waitingGameMaxAge, botBackfillAfter)
 
 	// Handle graceful shutdown
 	stop := make(chan os.Signal, 1)
@@ -51,16 +110,24 @@ func main() {
 }
 
 type Arbiter struct {
-	redisClient *redisclient.RedisClient
-	stopChan    chan struct{}
-	running     bool
+	redisClient       *redisclient.RedisClient
+	postgresClient    *postgresclient.PostgresClient
+	wordService       *word.Service
+	waitingGameMaxAge time.Duration
+	botBackfillAfter  time.Duration
+	stopChan          chan struct{}
+	running           bool
 }
 
-func NewArbiter(r *redisclient.RedisClient) *Arbiter {
+func NewArbiter(r *redisclient.RedisClient, p *postgresclient.PostgresClient, w *word.Service, waitingGameMaxAge time.Duration, botBackfillAfter time.Duration) *Arbiter {
 	return &Arbiter{
-		redisClient: r,
-		stopChan:    make(chan struct{}),
-		running:     false,
+		redisClient:       r,
+		postgresClient:    p,
+		wordService:       w,
+		waitingGameMaxAge: waitingGameMaxAge,
+		botBackfillAfter:  botBackfillAfter,
+		stopChan:          make(chan struct{}),
+		running:           false,
 	}
 }
 
@@ -68,13 +135,21 @@ func (a *Arbiter) Run() {
 	a.running = true
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
+	botTicker := time.NewTicker(botTurnPollInterval)
+	defer botTicker.Stop()
 
-	log.Printf("Arbiter polling every %v for expired games", pollInterval)
+	log.Printf("Arbiter polling every %v for expired and completed games (bots join after %v, waiting games expire after %v)", pollInterval, a.botBackfillAfter, a.waitingGameMaxAge)
 
 	for {
 		select {
 		case <-ticker.C:
 			a.processExpiredGames()
+			a.processMatchmaking()
+			a.processBotBackfill()
+			a.processStaleWaitingGames()
+			a.processCompletedGames()
+		case <-botTicker.C:
+			a.processBotTurns()
 		case <-a.stopChan:
 			log.Println("Arbiter stopped")
 			return
@@ -106,4 +181,182 @@ func (a *Arbiter) processExpiredGames() {
 		log.Printf("Game %s ended - winner: %s (opponent timed out)", result.GameID, result.WinnerID)
 	}
 }
+
+// processMatchmaking runs matchmaking again for the online games that have waited longest.
+// Players only search once when they ask for a game, so without this two waiting players
+// would never be paired however far their rating windows widened
+func (a *Arbiter) processMatchmaking() {
+	gameIDs, err := a.redisClient.GetMatchmakingGames(matchmakingBatchSize)
+	if err != nil {
+		log.Printf("Error fetching games in matchmaking: %v", err)
+		return
+	}
+
+	for _, gameID := range gameIDs {
+		game, err := a.redisClient.GetGame(gameID)
+		if err != nil {
+			log.Printf("Error getting game %s for matchmaking: %v", gameID, err)
+			continue
+		}
+		// The game hash has expired without the game leaving matchmaking
+		if game.ID == "" {
+			if err := a.redisClient.RemoveFromMatchmaking(gameID); err != nil {
+				log.Printf("Error removing game %s from matchmaking: %v", gameID, err)
+			}
+			continue
+		}
+		wordLength := game.WordLength
+		if wordLength == 0 {
+			wordLength = entities.DefaultWordLength
+		}
+		difficulty := game.Difficulty
+		if difficulty == "" {
+			difficulty = entities.DifficultyMedium
+		}
+		seed := word.NewSeed()
+		startWord, err := a.wordService.PickStartWord(wordLength, difficulty, seed)
+		if err != nil {
+			log.Printf("Error picking a start word for waiting game %s: %v", gameID, err)
+			continue
+		}
+
+		// Games matched or cancelled since they were read are skipped by the script
+		matchedID, _, matched, err := a.redisClient.AtomicMatchWaitingGame(game, startWord, seed)
+		if err != nil {
+			log.Printf("Error matching waiting game %s: %v", gameID, err)
+			continue
+		}
+		if matched {
+			log.Printf("Waiting game %s matched into game %s (player: %s)", gameID, matchedID, game.Player1ID)
+		}
+	}
+}
+
+// processBotBackfill gives online games that have waited botBackfillAfter without an opponent
+// a bot of about the creator's strength. The game is unrated from then on
+func (a *Arbiter) processBotBackfill() {
+	if a.botBackfillAfter <= 0 {
+		return
+	}
+
+	// Only games still in matchmaking are candidates, private games wait for the friend they were created for
+	gameIDs, err := a.redisClient.GetMatchmakingGamesOlderThan(a.botBackfillAfter, batchSize)
+	if err != nil {
+		log.Printf("Error fetching waiting games for bot backfill: %v", err)
+		return
+	}
+
+	for _, gameID := range gameIDs {
+		game, err := a.redisClient.GetGame(gameID)
+		if err != nil {
+			log.Printf("Error getting game %s for bot backfill: %v", gameID, err)
+			continue
+		}
+		// The game hash has expired without the game leaving matchmaking
+		if game.ID == "" {
+			if err := a.redisClient.RemoveFromMatchmaking(gameID); err != nil {
+				log.Printf("Error removing game %s from matchmaking: %v", gameID, err)
+			}
+			continue
+		}
+
+		bot, _ := entities.GetBotByLevel(entities.BotLevelForRating(game.Player1Rating))
+
+		wordLength := game.WordLength
+		if wordLength == 0 {
+			wordLength = entities.DefaultWordLength
+		}
+		difficulty := game.Difficulty
+		if difficulty == "" {
+			difficulty = entities.DifficultyMedium
+		}
+		seed := word.NewSeed()
+		startWord, err := a.wordService.PickStartWord(wordLength, difficulty, seed)
+		if err != nil {
+			log.Printf("Error picking a start word for bot backfill of game %s: %v", gameID, err)
+			continue
+		}
+
+		if err := a.redisClient.AtomicJoinBotToGame(gameID, bot, startWord, seed); err != nil {
+			// A human joined first
+			continue
+		}
+
+		// The bot connects straight away, starting the game if the creator is already connected
+		if _, _, _, err := a.redisClient.AtomicJoinGameSession(gameID); err != nil {
+			log.Printf("Error connecting bot to game %s: %v", gameID, err)
+			continue
+		}
+
+		log.Printf("Game %s backfilled with %s (creator: %s)", gameID, bot.Name, game.Player1ID)
+	}
+}
+
+// processStaleWaitingGames removes games that nobody joined within waitingGameMaxAge
+func (a *Arbiter) processStaleWaitingGames() {
+	expiredGames, err := a.redisClient.AtomicExpireWaitingGames(a.waitingGameMaxAge, batchSize)
+	if err != nil {
+		log.Printf("Error processing stale waiting games: %v", err)
+		return
+	}
+
+	for _, result := range expiredGames {
+		log.Printf("Game %s removed from matchmaking - no opponent joined (creator: %s)", result.GameID, result.Player1ID)
+	}
+}
+
+// processCompletedGames writes completed games and their move history to Postgres.
+// Games stay in the persist queue until the write succeeds, so a crash mid-batch is retried
+func (a *Arbiter) processCompletedGames() {
+	gameIDs, err := a.redisClient.GetGamesToPersist(batchSize)
+	if err != nil {
+		log.Printf("Error fetching games to persist: %v", err)
+		return
+	}
+
+	for _, gameID := range gameIDs {
+		if err := a.persistGame(gameID); err != nil {
+			log.Printf("Error persisting game %s: %v", gameID, err)
+			continue
+		}
+
+		if err := a.redisClient.RemoveGameFromPersistQueue(gameID); err != nil {
+			log.Printf("Error removing game %s from persist queue: %v", gameID, err)
+		}
+	}
+}
+
+func (a *Arbiter) persistGame(gameID string) error {
+	game, err := a.redisClient.GetGame(gameID)
+	if err != nil {
+		return err
+	}
+
+	// Game hash has expired, nothing left to persist
+	if game.ID == "" {
+		log.Printf("Game %s no longer in Redis, skipping persist", gameID)
+		return nil
+	}
+
+	moveRecords, err := a.redisClient.GetMoves(gameID)
+	if err != nil {
+		return err
+	}
+
+	moves := make([]entities.GameMove, 0, len(moveRecords))
+	for _, move := range moveRecords {
+		moves = append(moves, entities.GameMove{
+			PlayerID:   move.PlayerID,
+			PlayerName: move.PlayerName,
+			Word:       move.Word,
+			Timestamp:  move.Timestamp,
+		})
+	}
+
+	if err := a.postgresClient.SaveCompletedGame(game, moves); err != nil {
+		return err
+	}
+
+	log.Printf("Game %s persisted with %d moves", gameID, len(moves))
+	return nil
 }
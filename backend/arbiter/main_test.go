@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/redisclient"
+	"github.com/simonPacker7/Delta/backend/shared/word"
+)
+
+// Private games waiting longer than an online game must not keep it from being backfilled
+func TestBotBackfillSkipsPrivateGames(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := redisclient.NewRedisClient(redisclient.RedisConfig{Addr: mr.Addr()})
+	arbiter := NewArbiter(redisClient, nil, word.NewTestService(), time.Hour, time.Second)
+
+	createdAt := time.Now().Add(-time.Minute).UnixMilli()
+	for i := 0; i < batchSize*2; i++ {
+		err := redisClient.CreateGame(entities.Game{
+			ID:          redisclient.GenerateId(),
+			Type:        entities.GameTypePrivate,
+			Status:      entities.GameStatusWaiting,
+			JoinCode:    redisclient.GenerateGameCode(),
+			Player1ID:   fmt.Sprintf("friend-%d", i),
+			Player1Name: fmt.Sprintf("Friend %d", i),
+			CreatedAt:   createdAt - int64(i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gameID := redisclient.GenerateId()
+	err := redisClient.CreateGame(entities.Game{
+		ID:            gameID,
+		Type:          entities.GameTypeOnline,
+		Status:        entities.GameStatusWaiting,
+		Player1ID:     "player-1",
+		Player1Name:   "Player 1",
+		Player1Rating: 1000,
+		Rated:         true,
+		CreatedAt:     createdAt + 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := redisClient.PushToMatchmakingQueue(gameID, 1000, createdAt+1); err != nil {
+		t.Fatal(err)
+	}
+
+	arbiter.processBotBackfill()
+
+	game, err := redisClient.GetGame(gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Status != entities.GameStatusReady || game.BotLevel == "" {
+		t.Fatalf("expected a bot to join the online game, got %s with bot level %q", game.Status, game.BotLevel)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/simonPacker7/Delta/backend/cmd/wordmap
+
+go 1.25.4
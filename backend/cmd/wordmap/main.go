@@ -0,0 +1,166 @@
+// Command wordmap builds the word map and start word assets from a plain word list.
+//
+// Usage:
+//
+//	go run . -words words.txt -out ../../shared/assets
+//
+// For each word length it writes {length}-WordMap.json, mapping every word to the
+// words one letter change away, and {length}-StartWords.json, listing the words
+// with enough moves to start a game from
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	wordsPath := flag.String("words", "", "newline separated word list")
+	outDir := flag.String("out", ".", "directory to write the assets to")
+	lengthsFlag := flag.String("lengths", "3,4,5,6", "comma separated word lengths to build")
+	minBranching := flag.Int("min-branching", 5, "minimum number of moves from a start word")
+	flag.Parse()
+
+	if *wordsPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	lengths, err := parseLengths(*lengthsFlag)
+	if err != nil {
+		log.Fatalf("Invalid lengths: %v", err)
+	}
+
+	words, err := loadWords(*wordsPath)
+	if err != nil {
+		log.Fatalf("Failed to load word list: %v", err)
+	}
+
+	for _, length := range lengths {
+		wordMap := buildWordMap(words[length])
+		startWords := pickStartWords(wordMap, *minBranching)
+
+		if err := writeAssets(*outDir, length, wordMap, startWords); err != nil {
+			log.Fatalf("Failed to write %d letter assets: %v", length, err)
+		}
+
+		log.Printf("%d letters: %d words in map, %d start words", length, len(wordMap), len(startWords))
+	}
+}
+
+func parseLengths(value string) ([]int, error) {
+	var lengths []int
+	for _, part := range strings.Split(value, ",") {
+		length, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if length < 2 {
+			return nil, fmt.Errorf("word length %d is too short", length)
+		}
+		lengths = append(lengths, length)
+	}
+	return lengths, nil
+}
+
+// loadWords reads the word list and groups its words by length.
+// Words are upper cased, duplicates and anything that isn't purely A-Z are dropped
+func loadWords(path string) (map[int][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	seen := make(map[string]bool)
+	words := make(map[int][]string)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+		if word == "" || seen[word] || !isAlpha(word) {
+			continue
+		}
+		seen[word] = true
+		words[len(word)] = append(words[len(word)], word)
+	}
+
+	return words, scanner.Err()
+}
+
+func isAlpha(word string) bool {
+	for _, r := range word {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// buildWordMap computes the one letter change graph between words of the same length.
+// Words are bucketed by each pattern with one letter blanked out, so two words are
+// neighbours exactly when they share a bucket. Words with no neighbours are left out
+func buildWordMap(words []string) map[string][]string {
+	buckets := make(map[string][]string)
+	for _, word := range words {
+		for i := range word {
+			pattern := word[:i] + "_" + word[i+1:]
+			buckets[pattern] = append(buckets[pattern], word)
+		}
+	}
+
+	wordMap := make(map[string][]string)
+	for _, bucket := range buckets {
+		for _, word := range bucket {
+			for _, neighbour := range bucket {
+				if neighbour != word {
+					wordMap[word] = append(wordMap[word], neighbour)
+				}
+			}
+		}
+	}
+
+	for word := range wordMap {
+		slices.Sort(wordMap[word])
+	}
+
+	return wordMap
+}
+
+// pickStartWords returns the words with at least minBranching moves, sorted
+func pickStartWords(wordMap map[string][]string, minBranching int) []string {
+	startWords := make([]string, 0)
+	for word, moves := range wordMap {
+		if len(moves) >= minBranching {
+			startWords = append(startWords, word)
+		}
+	}
+	slices.Sort(startWords)
+	return startWords
+}
+
+// writeAssets writes the word map and start words in the format the services load
+func writeAssets(outDir string, length int, wordMap map[string][]string, startWords []string) error {
+	wordMapData, err := json.Marshal(wordMap)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(outDir, fmt.Sprintf("%d-WordMap.json", length)), wordMapData, 0o644)
+	if err != nil {
+		return err
+	}
+
+	startWordsData, err := json.MarshalIndent(startWords, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, fmt.Sprintf("%d-StartWords.json", length)), startWordsData, 0o644)
+}
@@ -1,27 +1,48 @@
 package main
 
 import (
-	"context"
 	"encoding/json"
 	"log"
+	"slices"
 	"sync"
+	"time"
 
-	"github.com/redis/go-redis/v9"
-	"github.com/simonPacker7/Delta/backend/game-service/word"
+	"github.com/simonPacker7/Delta/backend/shared/entities"
 	"github.com/simonPacker7/Delta/backend/shared/redisclient"
+	"github.com/simonPacker7/Delta/backend/shared/word"
+)
+
+const (
+	// How long each read of the game event streams blocks for.
+	// Also the longest a newly joined game waits before its stream is read
+	streamReadBlock = 250 * time.Millisecond
+
+	// Maximum events read per stream in one read
+	streamReadCount = 100
+
+	// How long players stay subscribed to a game after it ends, so they can arrange a rematch
+	rematchWindow = 60 * time.Second
+
+	// Reads of the events a resuming client missed before falling back to a snapshot,
+	// when live events keep arriving while they are read
+	maxResumeAttempts = 3
 )
 
 // ClientAction represents an incoming message from a client
 type ClientAction struct {
-	Action string `json:"action"` // "join_game", "leave_game", "submit_word", "forfeit"
-	GameID string `json:"gameId"`
-	Word   string `json:"word,omitempty"`
+	Action  string `json:"action"` // "join_game", "resume_game", "spectate_game", "leave_game", "start_game", "submit_word", "forfeit", "offer_draw", "accept_draw", "decline_draw", "offer_rematch", "accept_rematch", "request_hint", "undo_move"
+	GameID  string `json:"gameId"`
+	Word    string `json:"word,omitempty"`
+	LastSeq int64  `json:"lastSeq,omitempty"` // resume_game: seq of the last game event the client saw
 }
 
 // GameMessage represents a message to broadcast to game clients
+// Game events carry a per-game sequence number. Events can be delivered more than once
+// around a resume, so clients should ignore any event with a seq they have already seen
 type GameMessage struct {
 	Type    string      `json:"type"` // "game_ready", "game_start", "move", "game_ended", etc.
 	GameID  string      `json:"gameId"`
+	Seq     int64       `json:"seq,omitempty"`
 	Payload interface{} `json:"payload,omitempty"`
 }
 
@@ -32,6 +53,9 @@ type Hub struct {
 	// All connected clients mapped by UserID
 	clients map[string]*Client
 
+	// Sequence number of the last event delivered for each game with local clients
+	streamCursors map[string]int64
+
 	// Channel for client actions that need processing
 	actions chan *ClientActionRequest
 
@@ -39,7 +63,8 @@ type Hub struct {
 
 	unregister chan *Client
 
-	rdb *redis.Client
+	// Games whose rematch window has closed, cleaned up on the Run goroutine like every other client change
+	cleanup chan string
 
 	redisClient *redisclient.RedisClient
 
@@ -55,21 +80,22 @@ type ClientActionRequest struct {
 	Action *ClientAction
 }
 
-func createHub(rdb *redis.Client, redisClient *redisclient.RedisClient, wordService *word.Service) *Hub {
+func createHub(redisClient *redisclient.RedisClient, wordService *word.Service) *Hub {
 	return &Hub{
-		actions:     make(chan *ClientActionRequest, 256),
-		register:    make(chan *Client, 256),
-		unregister:  make(chan *Client, 256),
-		games:       make(map[string]map[*Client]bool),
-		clients:     make(map[string]*Client),
-		rdb:         rdb,
-		redisClient: redisClient,
-		wordService: wordService,
+		actions:       make(chan *ClientActionRequest, 256),
+		register:      make(chan *Client, 256),
+		unregister:    make(chan *Client, 256),
+		cleanup:       make(chan string, 256),
+		games:         make(map[string]map[*Client]bool),
+		clients:       make(map[string]*Client),
+		streamCursors: make(map[string]int64),
+		redisClient:   redisClient,
+		wordService:   wordService,
 	}
 }
 
 func (h *Hub) Run() {
-	// Start listening to Redis Pub/Sub in background
+	// Start reading game event streams in background
 	go h.ListenToRedis()
 
 	// Main event loop
@@ -83,6 +109,9 @@ func (h *Hub) Run() {
 
 		case actionReq := <-h.actions:
 			h.handleAction(actionReq)
+
+		case gameID := <-h.cleanup:
+			h.cleanupGame(gameID)
 		}
 	}
 }
@@ -91,6 +120,7 @@ func (h *Hub) handleRegister(client *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	// A reconnecting user replaces their old connection, which cleans itself up when its read fails
 	h.clients[client.UserID] = client
 	log.Printf("Client registered: %s", client.UserID)
 }
@@ -105,7 +135,8 @@ func (h *Hub) handleUnregister(client *Client) {
 	defer h.mu.Unlock()
 
 	// Remove from clients map and close send channel
-	if _, ok := h.clients[client.UserID]; ok {
+	// Only if this connection hasn't already been replaced by a reconnect
+	if existing, ok := h.clients[client.UserID]; ok && existing == client {
 		delete(h.clients, client.UserID)
 		close(client.Send)
 	}
@@ -120,71 +151,223 @@ func (h *Hub) handleAction(actionReq *ClientActionRequest) {
 	switch action.Action {
 	case "join_game":
 		h.handleJoinGame(client, action.GameID)
+	case "resume_game":
+		h.handleResumeGame(client, action.GameID, action.LastSeq)
+	case "spectate_game":
+		h.handleSpectateGame(client, action.GameID)
 	case "leave_game":
 		h.handleLeaveGame(client)
+	case "start_game":
+		h.handleStartGame(client, action.GameID)
 	case "submit_word":
 		h.handleSubmitWord(client, action.GameID, action.Word)
 	case "forfeit":
 		h.handleForfeit(client, action.GameID)
+	case "offer_draw":
+		h.handleDraw(client, action.GameID, "offer")
+	case "accept_draw":
+		h.handleDraw(client, action.GameID, "accept")
+	case "decline_draw":
+		h.handleDraw(client, action.GameID, "decline")
+	case "offer_rematch":
+		h.handleRematch(client, action.GameID, false)
+	case "accept_rematch":
+		h.handleRematch(client, action.GameID, true)
+	case "request_hint":
+		h.handleHint(client, action.GameID)
+	case "undo_move":
+		h.handleUndo(client, action.GameID)
 	default:
 		log.Printf("Unknown action: %s", action.Action)
 	}
 }
 
 func (h *Hub) handleJoinGame(client *Client, gameID string) {
+	lastSeq, ok := h.joinGameSession(client, gameID)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	h.addClientToGame(client, gameID, lastSeq)
+	h.mu.Unlock()
+
+	// Send join confirmation to client
+	h.sendToClient(client, GameMessage{
+		Type:   "joined_game",
+		GameID: gameID,
+	})
+
+	// If the game just started, game_started is delivered through the game's event stream
+}
+
+// joinGameSession leaves the client's previous game and joins gameID in Redis.
+// Returns the sequence number of the last game event before the join, and false if the join failed
+func (h *Hub) joinGameSession(client *Client, gameID string) (int64, bool) {
 	// Leave previous game if in one (with Redis update)
-	if client.GameID != "" && client.GameID != gameID {
+	if client.GameID != "" && (client.GameID != gameID || client.Spectating) {
 		h.leaveGameInternal(client)
 	}
 
 	// Join the game session in Redis (atomic increment of connected_count)
-	connectedCount, gameStarted, err := h.redisClient.AtomicJoinGameSession(gameID)
+	connectedCount, gameStarted, lastSeq, err := h.redisClient.AtomicJoinGameSession(gameID)
 	if err != nil {
 		log.Printf("Error joining game session: %v", err)
 		h.sendErrorToClient(client, "join_failed", err.Error())
+		return 0, false
+	}
+
+	log.Printf("Client %s joined game %s (connected: %d, started: %v)", client.UserID, gameID, connectedCount, gameStarted)
+	return lastSeq, true
+}
+
+// addClientToGame adds a client to the local game map. The first local client for a game
+// starts event delivery after lastSeq. Must be called with h.mu held
+func (h *Hub) addClientToGame(client *Client, gameID string, lastSeq int64) {
+	client.GameID = gameID
+	if h.games[gameID] == nil {
+		h.games[gameID] = make(map[*Client]bool)
+	}
+	h.games[gameID][client] = true
+
+	if _, ok := h.streamCursors[gameID]; !ok {
+		h.streamCursors[gameID] = lastSeq
+	}
+}
+
+// handleSpectateGame subscribes a client to a live game's events without joining it as a player.
+// The spectator is sent a game_state snapshot, then receives every later event like the players
+func (h *Hub) handleSpectateGame(client *Client, gameID string) {
+	if client.GameID != "" {
+		h.leaveGameInternal(client)
+	}
+
+	spectatorCount, lastSeq, err := h.redisClient.AtomicUpdateSpectators(gameID, 1)
+	if err != nil {
+		log.Printf("Error spectating game: %v", err)
+		h.sendErrorToClient(client, "spectate_failed", err.Error())
 		return
 	}
 
-	// Add client to local game map
 	h.mu.Lock()
 	client.GameID = gameID
+	client.Spectating = true
 	if h.games[gameID] == nil {
 		h.games[gameID] = make(map[*Client]bool)
 	}
 	h.games[gameID][client] = true
+
+	// First local client for this game, start delivering events after the snapshot
+	if _, ok := h.streamCursors[gameID]; !ok {
+		h.streamCursors[gameID] = lastSeq
+	}
 	h.mu.Unlock()
 
-	log.Printf("Client %s joined game %s (connected: %d, started: %v)", client.UserID, gameID, connectedCount, gameStarted)
+	log.Printf("Client %s spectating game %s (spectators: %d)", client.UserID, gameID, spectatorCount)
 
-	// Send join confirmation to client
 	h.sendToClient(client, GameMessage{
-		Type:   "joined_game",
+		Type:   "spectating_game",
 		GameID: gameID,
 	})
+	h.sendGameSnapshot(client, gameID)
+}
 
-	// If game just started (both players connected), fetch game state and broadcast
-	if gameStarted {
-		game, err := h.redisClient.GetGame(gameID)
-		if err != nil {
-			log.Printf("Error getting game state: %v", err)
-			return
+// handleResumeGame rejoins a game after a dropped connection and replays every event after lastSeq.
+// If the event log can't cover the gap the client is sent a full game_state snapshot instead.
+// The replay is sent under the same lock the stream listener delivers with, so live events can't
+// arrive before, between or on top of the replayed ones. The events themselves are read before taking it
+func (h *Hub) handleResumeGame(client *Client, gameID string, lastSeq int64) {
+	joinSeq, ok := h.joinGameSession(client, gameID)
+	if !ok {
+		return
+	}
+
+	// Events are read without holding the lock, so broadcasts aren't held up by the round trip to Redis.
+	// If the stream listener delivers events to this game's other local clients in the meantime, the
+	// read no longer covers everything the client would miss, so it is read again
+	for attempt := 0; attempt < maxResumeAttempts; attempt++ {
+		events, complete, err := h.redisClient.GetGameEventsSince(gameID, lastSeq)
+		if err != nil || !complete {
+			if err != nil {
+				log.Printf("Error reading events for game %s: %v", gameID, err)
+			}
+			break
+		}
+
+		readUpTo := lastSeq
+		if len(events) > 0 {
+			readUpTo = events[len(events)-1].Seq
 		}
 
-		// Broadcast game start to all clients in this game
-		h.broadcastGameMessage(gameID, GameMessage{
-			Type:   "game_started",
+		h.mu.Lock()
+		if cursor, ok := h.streamCursors[gameID]; ok && cursor > readUpTo {
+			h.mu.Unlock()
+			continue
+		}
+		h.addClientToGame(client, gameID, joinSeq)
+		h.sendToClient(client, GameMessage{
+			Type:   "joined_game",
 			GameID: gameID,
-			Payload: map[string]interface{}{
-				"currentWord":   game.CurrentWord,
-				"currentTurnId": game.CurrentTurnID,
-				"player1Id":     game.Player1ID,
-				"player1Name":   game.Player1Name,
-				"player2Id":     game.Player2ID,
-				"player2Name":   game.Player2Name,
-				"startWord":     game.CurrentWord,
-			},
 		})
+
+		// Events up to the cursor have already gone to the game's other local clients. Any after it
+		// haven't been read by the stream listener yet, so they go to everyone and the cursor moves past them
+		cursor := h.streamCursors[gameID]
+		for _, event := range events {
+			if event.Seq <= cursor {
+				select {
+				case client.Send <- event.Data:
+				default:
+					log.Printf("Client %s send buffer full during replay", client.UserID)
+				}
+				continue
+			}
+			h.broadcastLocked(event.GameID, event.Data)
+			cursor = event.Seq
+		}
+		h.streamCursors[gameID] = cursor
+		h.mu.Unlock()
+
+		log.Printf("Replayed %d events to client %s for game %s", len(events), client.UserID, gameID)
+		return
 	}
+
+	// The missed events can't be replayed, so the client gets the whole game instead
+	h.mu.Lock()
+	h.addClientToGame(client, gameID, joinSeq)
+	h.sendToClient(client, GameMessage{
+		Type:   "joined_game",
+		GameID: gameID,
+	})
+	h.mu.Unlock()
+	h.sendGameSnapshot(client, gameID)
+}
+
+// sendGameSnapshot sends the full state of a game, including move history, to one client
+func (h *Hub) sendGameSnapshot(client *Client, gameID string) {
+	game, err := h.redisClient.GetGame(gameID)
+	if err != nil || game.ID == "" {
+		log.Printf("Error getting game state for snapshot: %v", err)
+		h.sendErrorToClient(client, "resume_failed", "game_not_found")
+		return
+	}
+
+	moves, err := h.redisClient.GetMoves(gameID)
+	if err != nil {
+		log.Printf("Error getting moves for snapshot: %v", err)
+		h.sendErrorToClient(client, "resume_failed", "server_error")
+		return
+	}
+
+	h.sendToClient(client, GameMessage{
+		Type:   "game_state",
+		GameID: gameID,
+		Seq:    game.EventSeq,
+		Payload: map[string]interface{}{
+			"game":  game,
+			"moves": moves,
+		},
+	})
 }
 
 func (h *Hub) leaveGameInternal(client *Client) {
@@ -194,8 +377,12 @@ func (h *Hub) leaveGameInternal(client *Client) {
 
 	gameID := client.GameID
 
-	// Decrement connected_count in Redis
-	if err := h.redisClient.AtomicLeaveGameSession(gameID); err != nil {
+	// Decrement connected_count in Redis, or the spectator count for spectators
+	if client.Spectating {
+		if _, _, err := h.redisClient.AtomicUpdateSpectators(gameID, -1); err != nil {
+			log.Printf("Error leaving spectators: %v", err)
+		}
+	} else if err := h.redisClient.AtomicLeaveGameSession(gameID); err != nil {
 		log.Printf("Error leaving game session: %v", err)
 	}
 
@@ -205,9 +392,11 @@ func (h *Hub) leaveGameInternal(client *Client) {
 		delete(gameClients, client)
 		if len(gameClients) == 0 {
 			delete(h.games, gameID)
+			delete(h.streamCursors, gameID)
 		}
 	}
 	client.GameID = ""
+	client.Spectating = false
 	h.mu.Unlock()
 }
 
@@ -220,12 +409,66 @@ func (h *Hub) handleLeaveGame(client *Client) {
 	h.leaveGameInternal(client)
 }
 
+// handleStartGame starts a multi-player lobby for its host. The game_started event
+// is delivered to everyone in the lobby through the game's event stream
+func (h *Hub) handleStartGame(client *Client, gameID string) {
+	if client.GameID != gameID || client.Spectating {
+		h.sendErrorToClient(client, "start_failed", "not_in_game")
+		return
+	}
+
+	game, err := h.redisClient.GetGame(gameID)
+	if err != nil || game.ID == "" {
+		h.sendErrorToClient(client, "start_failed", "game_not_found")
+		return
+	}
+
+	wordLength := game.WordLength
+	if wordLength == 0 {
+		wordLength = entities.DefaultWordLength
+	}
+	difficulty := game.Difficulty
+	if difficulty == "" {
+		difficulty = entities.DifficultyMedium
+	}
+	seed := word.NewSeed()
+	startWord, err := h.wordService.PickStartWord(wordLength, difficulty, seed)
+	if err != nil {
+		log.Printf("Error picking a start word for game %s: %v", gameID, err)
+		h.sendErrorToClient(client, "start_failed", err.Error())
+		return
+	}
+
+	startWord, err = h.redisClient.AtomicStartLobbyGame(gameID, client.UserID, startWord, seed)
+	if err != nil {
+		log.Printf("Error starting game %s: %v", gameID, err)
+		h.sendErrorToClient(client, "start_failed", err.Error())
+		return
+	}
+
+	log.Printf("Client %s started game %s with %d players, start word: %s", client.UserID, gameID, len(game.Players), startWord)
+}
+
 func (h *Hub) handleForfeit(client *Client, gameID string) {
-	if client.GameID != gameID {
+	if client.GameID != gameID || client.Spectating {
 		h.sendErrorToClient(client, "forfeit_failed", "not_in_game")
 		return
 	}
 
+	game, err := h.redisClient.GetGame(gameID)
+	if err != nil || game.ID == "" {
+		h.sendErrorToClient(client, "forfeit_failed", "game_not_found")
+		return
+	}
+
+	// A practice player left on a dead end ends the game as out of moves rather than forfeiting it
+	if game.Type == entities.GameTypePractice && game.Status == entities.GameStatusActive {
+		if moves, err := h.unplayedMoves(game, game.CurrentWord); err == nil && len(moves) == 0 {
+			h.endGameIfNoMovesLeft(game, game.CurrentWord, client.UserID)
+			return
+		}
+	}
+
 	// Atomically forfeit the game
 	winnerID, err := h.redisClient.AtomicForfeitGame(gameID, client.UserID)
 	if err != nil {
@@ -234,15 +477,40 @@ func (h *Hub) handleForfeit(client *Client, gameID string) {
 		return
 	}
 
-	log.Printf("Client %s forfeited game %s, winner: %s", client.UserID, gameID, winnerID)
+	switch {
+	case game.Type == entities.GameTypePractice:
+		log.Printf("Client %s ended practice game %s", client.UserID, gameID)
+	case game.MaxPlayers > 2 && winnerID == "":
+		log.Printf("Client %s forfeited and was knocked out of game %s, play continues", client.UserID, gameID)
+	default:
+		log.Printf("Client %s forfeited game %s, winner: %s", client.UserID, gameID, winnerID)
+	}
 
-	// The AtomicForfeitGame already publishes the game_ended event via Redis Pub/Sub,
+	// The AtomicForfeitGame already records the game_ended event in the game's stream,
 	// which will be picked up by ListenToRedis and broadcast to all clients.
-	// No need to broadcast here - it's handled by the Pub/Sub listener.
+	// No need to broadcast here - it's handled by the stream listener.
+}
+
+// handleDraw offers, accepts or declines a draw. The resulting draw_offered, draw_declined
+// or game_ended event is delivered to both players through the game's event stream
+func (h *Hub) handleDraw(client *Client, gameID string, action string) {
+	if client.GameID != gameID || client.Spectating {
+		h.sendErrorToClient(client, "draw_failed", "not_in_game")
+		return
+	}
+
+	result, err := h.redisClient.AtomicDrawAction(gameID, client.UserID, action)
+	if err != nil {
+		log.Printf("Error handling draw %s: %v", action, err)
+		h.sendErrorToClient(client, "draw_failed", err.Error())
+		return
+	}
+
+	log.Printf("Client %s draw %s in game %s: %s", client.UserID, action, gameID, result)
 }
 
 func (h *Hub) handleSubmitWord(client *Client, gameID string, word string) {
-	if client.GameID != gameID {
+	if client.GameID != gameID || client.Spectating {
 		h.sendErrorToClient(client, "submit_failed", "not_in_game")
 		return
 	}
@@ -255,6 +523,11 @@ func (h *Hub) handleSubmitWord(client *Client, gameID string, word string) {
 		return
 	}
 
+	if game.Type == entities.GameTypeRace {
+		h.handleRaceSubmit(client, game, word)
+		return
+	}
+
 	// Validate it's this player's turn
 	if game.CurrentTurnID != client.UserID {
 		h.sendErrorToClient(client, "submit_failed", "not_your_turn")
@@ -262,29 +535,43 @@ func (h *Hub) handleSubmitWord(client *Client, gameID string, word string) {
 	}
 
 	// Check if word has already been played (before validating move)
-	alreadyPlayed, err := h.redisClient.IsWordPlayed(gameID, word)
-	if err != nil {
-		log.Printf("Error checking if word played: %v", err)
-		h.sendErrorToClient(client, "submit_failed", "server_error")
-		return
+	if !game.AllowRepeats {
+		alreadyPlayed, err := h.redisClient.IsWordPlayed(gameID, word)
+		if err != nil {
+			log.Printf("Error checking if word played: %v", err)
+			h.sendErrorToClient(client, "submit_failed", "server_error")
+			return
+		}
+		if alreadyPlayed {
+			h.sendErrorToClient(client, "submit_failed", "word_already_played")
+			return
+		}
 	}
-	if alreadyPlayed {
-		h.sendErrorToClient(client, "submit_failed", "word_already_played")
+
+	// The game's start word came from the worker's dictionary, refuse to judge moves with a different one
+	if game.DictionaryVersion != "" && game.DictionaryVersion != h.wordService.Version() {
+		log.Printf("Game %s uses dictionary %s but this instance has %s", gameID, game.DictionaryVersion, h.wordService.Version())
+		h.sendErrorToClient(client, "submit_failed", "dictionary_mismatch")
 		return
 	}
 
-	// Validate the word is a valid move using word service
-	if !h.wordService.IsValidMove(game.CurrentWord, word) {
+	// Validate the word is a valid move using the word map for the game's word length
+	wordLength := game.WordLength
+	if wordLength == 0 {
+		wordLength = len(game.CurrentWord)
+	}
+	if !h.wordService.IsValidMove(wordLength, game.CurrentWord, word) {
 		h.sendErrorToClient(client, "submit_failed", "invalid_move")
 		return
 	}
 
-	// Get the player name from game state
-	playerName := game.Player1Name
-	if client.UserID == game.Player2ID {
-		playerName = game.Player2Name
+	if game.Type == entities.GameTypePractice {
+		h.handlePracticeSubmit(client, game, word)
+		return
 	}
 
+	playerName := gamePlayerName(game, client.UserID)
+
 	// Atomically submit the word (updates game state and resets timer)
 	success, newWord, nextTurnID, err := h.redisClient.AtomicSubmitWord(gameID, client.UserID, playerName, word)
 	if err != nil {
@@ -303,19 +590,62 @@ func (h *Hub) handleSubmitWord(client *Client, gameID string, word string) {
 		return
 	}
 
-	log.Printf("Client %s submitted word '%s' for game %s, next turn: %s", client.UserID, word, gameID, nextTurnID)
+	log.Printf("Client %s submitted word '%s' for game %s, next turn: %s", client.UserID, newWord, gameID, nextTurnID)
 
-	// Broadcast the move to all clients in this game
-	h.broadcastGameMessage(gameID, GameMessage{
-		Type:   "word_submitted",
-		GameID: gameID,
-		Payload: map[string]interface{}{
-			"playerId":      client.UserID,
-			"playerName":    playerName,
-			"word":          newWord,
-			"currentTurnId": nextTurnID,
-		},
-	})
+	// AtomicSubmitWord records the word_submitted event in the game's stream,
+	// the stream listener broadcasts it to every client in the game
+
+	h.endGameIfNoMovesLeft(game, newWord, nextTurnID)
+}
+
+// gamePlayerName looks up a player's name in the game state
+func gamePlayerName(game entities.Game, playerID string) string {
+	for _, player := range game.Players {
+		if player.ID == playerID {
+			return player.Name
+		}
+	}
+	if playerID == game.Player2ID {
+		return game.Player2Name
+	}
+	return game.Player1Name
+}
+
+// endGameIfNoMovesLeft ends the game straight away when the next player has no legal word
+// to play from currentWord, instead of leaving them to run out of time
+func (h *Hub) endGameIfNoMovesLeft(game entities.Game, currentWord string, nextTurnID string) {
+	moves, err := h.unplayedMoves(game, currentWord)
+	if err != nil {
+		log.Printf("Error getting played words: %v", err)
+		return
+	}
+	if len(moves) > 0 {
+		return
+	}
+
+	winnerID, err := h.redisClient.AtomicEndGameNoMoves(game.ID, nextTurnID, currentWord)
+	if err != nil {
+		log.Printf("Error ending game %s with no moves left: %v", game.ID, err)
+		return
+	}
+
+	log.Printf("Player %s has no moves left in game %s, winner: %s", nextTurnID, game.ID, winnerID)
+}
+
+// unplayedMoves returns the words that can be played from currentWord, leaving out played words unless the game allows repeats
+func (h *Hub) unplayedMoves(game entities.Game, currentWord string) ([]string, error) {
+	moves := h.wordService.Moves(currentWord)
+	if game.AllowRepeats || len(moves) == 0 {
+		return moves, nil
+	}
+
+	playedWords, err := h.redisClient.GetPlayedWords(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(slices.Clone(moves), func(word string) bool {
+		return slices.Contains(playedWords, word)
+	}), nil
 }
 
 // sendToClient sends a message to a specific client
@@ -357,22 +687,17 @@ func (h *Hub) broadcastGameMessage(gameID string, msg GameMessage) {
 // BroadcastToGame sends a message to all clients in a game
 func (h *Hub) BroadcastToGame(gameID string, message []byte) {
 	h.mu.RLock()
-	gameClients, ok := h.games[gameID]
-	if !ok {
-		h.mu.RUnlock()
-		return
-	}
+	defer h.mu.RUnlock()
 
-	// Copy client list to avoid holding lock during send
-	clientList := make([]*Client, 0, len(gameClients))
-	for client := range gameClients {
-		clientList = append(clientList, client)
-	}
-	h.mu.RUnlock()
+	h.broadcastLocked(gameID, message)
+}
 
+// broadcastLocked sends a message to all clients in a game. Sends never block,
+// so it is safe to call with h.mu held, which it must be
+func (h *Hub) broadcastLocked(gameID string, message []byte) {
 	// Send to each client, collect failures
 	var failedClients []*Client
-	for _, client := range clientList {
+	for client := range h.games[gameID] {
 		select {
 		case client.Send <- message:
 		default:
@@ -393,44 +718,71 @@ func (h *Hub) BroadcastToGame(gameID string, message []byte) {
 	}
 }
 
+// ListenToRedis reads the event streams of every game with local clients and broadcasts
+// each event in order. Every instance reads the same streams, so all clients see the same sequence
 func (h *Hub) ListenToRedis() {
-	ctx := context.Background()
-	// Subscribe to a pattern so we catch updates for ANY game
-	pubsub := h.rdb.PSubscribe(ctx, "game:*")
-	defer pubsub.Close()
-
-	ch := pubsub.Channel()
-
-	for msg := range ch {
-		// Extract gameID from channel name (format: "game:{gameId}")
-		gameID := msg.Channel[5:] // Remove "game:" prefix
+	for {
+		h.mu.RLock()
+		cursors := make(map[string]int64, len(h.streamCursors))
+		for gameID, seq := range h.streamCursors {
+			cursors[gameID] = seq
+		}
+		h.mu.RUnlock()
 
-		// Broadcast the raw message to all clients in this game
-		h.BroadcastToGame(gameID, []byte(msg.Payload))
+		events, err := h.redisClient.ReadGameEvents(cursors, streamReadBlock, streamReadCount)
+		if err != nil {
+			log.Printf("Error reading game events: %v", err)
+			time.Sleep(streamReadBlock)
+			continue
+		}
 
-		// Check if this is a game_ended event and clean up the game
-		var gameMsg GameMessage
-		if err := json.Unmarshal([]byte(msg.Payload), &gameMsg); err == nil {
-			if gameMsg.Type == "game_ended" || gameMsg.Type == "game_ended_timeout" {
-				h.cleanupGame(gameID)
+		for _, event := range events {
+			// Skip events for games that have since lost their local clients or were already delivered.
+			// Delivered under the lock so a resuming client's replay can't interleave with it
+			h.mu.Lock()
+			cursor, ok := h.streamCursors[event.GameID]
+			if !ok || event.Seq <= cursor {
+				h.mu.Unlock()
+				continue
+			}
+			h.streamCursors[event.GameID] = event.Seq
+			h.broadcastLocked(event.GameID, event.Data)
+			h.mu.Unlock()
+
+			// Check if this is a game_ended, matchmaking_expired or matchmaking_matched event and clean up the game.
+			// Ended games are kept a little longer so the players can arrange a rematch.
+			// A matched player is told which game to join instead of the deleted one
+			var gameMsg GameMessage
+			if err := json.Unmarshal(event.Data, &gameMsg); err == nil {
+				if gameMsg.Type == "matchmaking_expired" || gameMsg.Type == "matchmaking_matched" {
+					h.cleanupGame(event.GameID)
+				}
+				if gameMsg.Type == "game_ended" {
+					gameID := event.GameID
+					time.AfterFunc(rematchWindow, func() { h.cleanup <- gameID })
+				}
 			}
 		}
 	}
 }
 
 // cleanupGame removes all clients from a game and clears the game from the map
-// Called after a game ends to stop tracking clients for that game
+// Called on the Run goroutine once a game's rematch window has closed, to stop tracking clients for that game
 func (h *Hub) cleanupGame(gameID string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	if gameClients, ok := h.games[gameID]; ok {
-		// Clear the GameID from each client so they're no longer associated
+		// Clear the GameID from each client still in the game so they're no longer associated
 		for client := range gameClients {
-			client.GameID = ""
+			if client.GameID == gameID {
+				client.GameID = ""
+				client.Spectating = false
+			}
 		}
 		// Remove the game from the games map
 		delete(h.games, gameID)
 		log.Printf("Cleaned up game %s after end", gameID)
 	}
+	delete(h.streamCursors, gameID)
 }
@@ -0,0 +1,807 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/redisclient"
+	"github.com/simonPacker7/Delta/backend/shared/word"
+)
+
+// Two game-service instances sharing one Redis must deliver the same ordered
+// events to players connected to different instances
+func TestHubsShareGameEventsAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	words := word.NewTestService()
+
+	hubA := createHub(newTestRedisClient(mr), words)
+	hubB := createHub(newTestRedisClient(mr), words)
+	go hubA.Run()
+	go hubB.Run()
+
+	gameID := createTestGame(t, newTestRedisClient(mr), "COLD")
+
+	player1 := newTestClient(hubA, "player-1")
+	player2 := newTestClient(hubB, "player-2")
+
+	hubA.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	expectMessage(t, player1, "joined_game")
+
+	hubB.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	expectMessage(t, player2, "joined_game")
+
+	started1 := expectMessage(t, player1, "game_started")
+	started2 := expectMessage(t, player2, "game_started")
+	if started1.Seq != 1 || started2.Seq != 1 {
+		t.Fatalf("expected game_started to be event 1 on both instances, got %d and %d", started1.Seq, started2.Seq)
+	}
+
+	// Player 1 moves on instance A, player 2 must see it on instance B
+	hubA.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "CORD"}}
+	moved1 := expectMessage(t, player1, "word_submitted")
+	moved2 := expectMessage(t, player2, "word_submitted")
+	if moved1.Seq != 2 || moved2.Seq != 2 {
+		t.Fatalf("expected word_submitted to be event 2 on both instances, got %d and %d", moved1.Seq, moved2.Seq)
+	}
+
+	// Player 2 forfeits on instance B, player 1 must see it on instance A
+	hubB.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "forfeit", GameID: gameID}}
+	ended1 := expectMessage(t, player1, "game_ended")
+	ended2 := expectMessage(t, player2, "game_ended")
+	if ended1.Seq != 3 || ended2.Seq != 3 {
+		t.Fatalf("expected game_ended to be event 3 on both instances, got %d and %d", ended1.Seq, ended2.Seq)
+	}
+
+	payload, _ := ended1.Payload.(map[string]interface{})
+	if payload["winnerId"] != "player-1" {
+		t.Fatalf("expected player-1 to win, got %v", payload["winnerId"])
+	}
+}
+
+// A player left with no unplayed neighbour of the current word loses immediately
+func TestGameEndsWhenNoMovesLeft(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	hub := createHub(newTestRedisClient(mr), word.NewTestService())
+	go hub.Run()
+
+	gameID := createTestGame(t, newTestRedisClient(mr), "COLD")
+
+	player1 := newTestClient(hub, "player-1")
+	player2 := newTestClient(hub, "player-2")
+
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	expectMessage(t, player1, "game_started")
+
+	// COLD -> CORD -> WORD leaves player 1 on WORD, whose only neighbour CORD has been played
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "CORD"}}
+	expectMessage(t, player1, "word_submitted")
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "WORD"}}
+
+	ended := expectMessage(t, player1, "game_ended")
+	payload, _ := ended.Payload.(map[string]interface{})
+	if payload["winnerId"] != "player-2" || payload["reason"] != "no_moves_left" {
+		t.Fatalf("expected player-2 to win with no_moves_left, got %v", payload)
+	}
+}
+
+// A spectator gets a snapshot and later events without counting as a connected player
+func TestSpectatorWatchesGame(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedisClient(mr)
+
+	hub := createHub(redisClient, word.NewTestService())
+	go hub.Run()
+
+	gameID := createTestGame(t, newTestRedisClient(mr), "COLD")
+
+	player1 := newTestClient(hub, "player-1")
+	player2 := newTestClient(hub, "player-2")
+	spectator := newTestClient(hub, "spectator")
+
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	expectMessage(t, player1, "game_started")
+
+	hub.actions <- &ClientActionRequest{Client: spectator, Action: &ClientAction{Action: "spectate_game", GameID: gameID}}
+	expectMessage(t, spectator, "spectating_game")
+	snapshot := expectMessage(t, spectator, "game_state")
+	payload, _ := snapshot.Payload.(map[string]interface{})
+	moves, _ := payload["moves"].([]interface{})
+	if len(moves) != 1 {
+		t.Fatalf("expected the start word in the snapshot moves, got %v", payload["moves"])
+	}
+
+	counted := expectMessage(t, player1, "spectator_count")
+	if count, _ := counted.Payload.(map[string]interface{})["count"].(float64); count != 1 {
+		t.Fatalf("expected 1 spectator, got %v", counted.Payload)
+	}
+
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "CORD"}}
+	expectMessage(t, spectator, "word_submitted")
+
+	game, err := redisClient.GetGame(gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.ConnectedCount != 2 || game.SpectatorCount != 1 {
+		t.Fatalf("expected 2 players and 1 spectator, got %d and %d", game.ConnectedCount, game.SpectatorCount)
+	}
+}
+
+// A resuming client gets the events it missed once each and in order, followed by live events,
+// while another local client is already following the game
+func TestResumeReplaysMissedEventsInOrder(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	hub := createHub(newTestRedisClient(mr), word.NewTestService())
+	go hub.Run()
+
+	gameID := createTestGame(t, newTestRedisClient(mr), "COLD")
+
+	player1 := newTestClient(hub, "player-1")
+	player2 := newTestClient(hub, "player-2")
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	expectMessage(t, player2, "game_started")
+
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "CORD"}}
+	expectMessage(t, player2, "word_submitted")
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "offer_draw", GameID: gameID}}
+	expectMessage(t, player2, "draw_offered")
+
+	// Player 1 reconnects having only seen game_started, then player 2 declines the draw
+	resumed := newTestClient(hub, "player-1")
+	hub.actions <- &ClientActionRequest{Client: resumed, Action: &ClientAction{Action: "resume_game", GameID: gameID, LastSeq: 1}}
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "decline_draw", GameID: gameID}}
+
+	seqs := make([]int64, 0, 3)
+	timeout := time.After(5 * time.Second)
+	for len(seqs) < 3 {
+		select {
+		case data := <-resumed.Send:
+			var msg GameMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Seq > 0 {
+				seqs = append(seqs, msg.Seq)
+			}
+		case <-timeout:
+			t.Fatalf("expected 3 events after the resume, got %v", seqs)
+		}
+	}
+	if !slices.Equal(seqs, []int64{2, 3, 4}) {
+		t.Fatalf("expected events 2, 3 and 4 once each, got %v", seqs)
+	}
+	select {
+	case data := <-resumed.Send:
+		t.Fatalf("expected no more messages, got %s", data)
+	case <-time.After(2 * streamReadBlock):
+	}
+}
+
+// A draw offer accepted by the opponent ends the game with no winner
+func TestDrawAgreed(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	hub := createHub(newTestRedisClient(mr), word.NewTestService())
+	go hub.Run()
+
+	gameID := createTestGame(t, newTestRedisClient(mr), "COLD")
+
+	player1 := newTestClient(hub, "player-1")
+	player2 := newTestClient(hub, "player-2")
+
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	expectMessage(t, player1, "game_started")
+
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "offer_draw", GameID: gameID}}
+	expectMessage(t, player2, "draw_offered")
+
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "accept_draw", GameID: gameID}}
+	ended := expectMessage(t, player1, "game_ended")
+
+	payload, _ := ended.Payload.(map[string]interface{})
+	if payload["winnerId"] != "" || payload["reason"] != "draw_agreed" {
+		t.Fatalf("expected a draw with no winner, got %v", payload)
+	}
+}
+
+// Playing a word instead of answering a draw offer declines it, and the offerer is told
+func TestMoveDeclinesDraw(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	hub := createHub(newTestRedisClient(mr), word.NewTestService())
+	go hub.Run()
+
+	gameID := createTestGame(t, newTestRedisClient(mr), "COLD")
+
+	player1 := newTestClient(hub, "player-1")
+	player2 := newTestClient(hub, "player-2")
+
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	expectMessage(t, player2, "game_started")
+
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "offer_draw", GameID: gameID}}
+	expectMessage(t, player2, "draw_offered")
+
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "CORD"}}
+	moved := expectMessage(t, player2, "word_submitted")
+	declined := expectMessage(t, player2, "draw_declined")
+	if declined.Seq != moved.Seq+1 {
+		t.Fatalf("expected draw_declined right after the move, got seq %d after %d", declined.Seq, moved.Seq)
+	}
+	if payload, _ := declined.Payload.(map[string]interface{}); payload["playerId"] != "player-1" {
+		t.Fatalf("expected player-1 to have declined, got %v", payload)
+	}
+}
+
+// After a game ends the players can agree a rematch, which swaps who moves first
+func TestRematchAfterGameEnds(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedisClient(mr)
+
+	hubA := createHub(redisClient, word.NewTestService())
+	hubB := createHub(newTestRedisClient(mr), word.NewTestService())
+	go hubA.Run()
+	go hubB.Run()
+
+	gameID := createTestGame(t, newTestRedisClient(mr), "COLD")
+
+	player1 := newTestClient(hubA, "player-1")
+	player2 := newTestClient(hubB, "player-2")
+
+	hubA.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	hubB.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	expectMessage(t, player1, "game_started")
+	mr.HSet("game:"+gameID, "rated", "1", "player1_rating", "1200", "player2_rating", "1200")
+
+	hubB.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "forfeit", GameID: gameID}}
+	expectMessage(t, player1, "game_ended")
+	expectMessage(t, player2, "game_ended")
+	ended, err := redisClient.GetGame(gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hubA.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "offer_rematch", GameID: gameID}}
+	expectMessage(t, player2, "rematch_offered")
+
+	hubB.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "accept_rematch", GameID: gameID}}
+	started1 := expectMessage(t, player1, "rematch_started")
+	expectMessage(t, player2, "rematch_started")
+
+	payload, _ := started1.Payload.(map[string]interface{})
+	newGameID, _ := payload["gameId"].(string)
+	rematch, err := redisClient.GetGame(newGameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rematch.Status != entities.GameStatusReady || rematch.Player1ID != "player-2" || rematch.CurrentTurnID != "player-2" {
+		t.Fatalf("expected a ready rematch with player-2 moving first, got %+v", rematch)
+	}
+
+	// Ratings carry the finished game's result even though it hasn't been persisted
+	if ended.Player1Delta <= 0 || rematch.Player1Rating != 1200+ended.Player2Delta || rematch.Player2Rating != 1200+ended.Player1Delta {
+		t.Fatalf("expected the rematch to start from the ratings after the game (deltas %d and %d), got %d and %d",
+			ended.Player1Delta, ended.Player2Delta, rematch.Player2Rating, rematch.Player1Rating)
+	}
+}
+
+// A lobby for three players starts when the host says so, rotates turns between everyone,
+// and knocks players out on forfeit or timeout until one is left
+func TestLobbyGameLastPlayerStanding(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedisClient(mr)
+
+	hub := createHub(redisClient, word.NewTestService())
+	go hub.Run()
+
+	gameID, joinCode := createTestLobby(t, redisClient, entities.GameTypePrivate, 3)
+	if _, _, err := redisClient.AtomicJoinPrivateGame(joinCode, "player-4", "player-4", 0, "CORD", 0, "", 0); err == nil || err.Error() != "game_full" {
+		t.Fatalf("expected the lobby to be full, got %v", err)
+	}
+
+	players := joinTestLobby(t, hub, gameID, 3)
+	hub.actions <- &ClientActionRequest{Client: players[0], Action: &ClientAction{Action: "start_game", GameID: gameID}}
+	started := expectMessage(t, players[2], "game_started")
+	payload, _ := started.Payload.(map[string]interface{})
+	if payload["currentWord"] != "COLD" || payload["currentTurnId"] != "player-1" {
+		t.Fatalf("expected player-1 to start from COLD, got %v", payload)
+	}
+
+	hub.actions <- &ClientActionRequest{Client: players[0], Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "CORD"}}
+	moved := expectMessage(t, players[2], "word_submitted")
+	if turn := moved.Payload.(map[string]interface{})["currentTurnId"]; turn != "player-2" {
+		t.Fatalf("expected player-2 to move next, got %v", turn)
+	}
+
+	// Player 2 forfeits on their turn, which passes to player 3
+	hub.actions <- &ClientActionRequest{Client: players[1], Action: &ClientAction{Action: "forfeit", GameID: gameID}}
+	eliminated := expectMessage(t, players[0], "player_eliminated")
+	payload, _ = eliminated.Payload.(map[string]interface{})
+	if payload["playerId"] != "player-2" || payload["currentTurnId"] != "player-3" {
+		t.Fatalf("expected player-2 out and player-3 to move, got %v", payload)
+	}
+
+	// Player 3 runs out of time, leaving player 1
+	mr.ZAdd("game:expire", 0, gameID)
+	if _, err := redisClient.AtomicClaimAndEndExpiredGames(10); err != nil {
+		t.Fatal(err)
+	}
+	ended := expectMessage(t, players[0], "game_ended")
+	payload, _ = ended.Payload.(map[string]interface{})
+	if payload["winnerId"] != "player-1" || payload["reason"] != "timeout" {
+		t.Fatalf("expected player-1 to win on timeout, got %v", payload)
+	}
+}
+
+// Teams take alternate turns and one player running out of time loses the game for their team
+func TestTeamGameTimeoutEliminatesTeam(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedisClient(mr)
+
+	hub := createHub(redisClient, word.NewTestService())
+	go hub.Run()
+
+	gameID, _ := createTestLobby(t, redisClient, entities.GameTypeTeam, 4)
+	players := joinTestLobby(t, hub, gameID, 4)
+
+	hub.actions <- &ClientActionRequest{Client: players[0], Action: &ClientAction{Action: "start_game", GameID: gameID}}
+	started := expectMessage(t, players[3], "game_started")
+	seats, _ := started.Payload.(map[string]interface{})["players"].([]interface{})
+	for i, seat := range seats {
+		if team, _ := seat.(map[string]interface{})["team"].(float64); int(team) != i%2+1 {
+			t.Fatalf("expected seat %d on team %d, got %v", i, i%2+1, seat)
+		}
+	}
+
+	hub.actions <- &ClientActionRequest{Client: players[0], Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "CORD"}}
+	moved := expectMessage(t, players[1], "word_submitted")
+	if turn := moved.Payload.(map[string]interface{})["currentTurnId"]; turn != "player-2" {
+		t.Fatalf("expected team 2 to move next, got %v", turn)
+	}
+
+	mr.ZAdd("game:expire", 0, gameID)
+	if _, err := redisClient.AtomicClaimAndEndExpiredGames(10); err != nil {
+		t.Fatal(err)
+	}
+	ended := expectMessage(t, players[3], "game_ended")
+	payload, _ := ended.Payload.(map[string]interface{})
+	if payload["winningTeam"] != float64(1) || payload["reason"] != "timeout" {
+		t.Fatalf("expected team 1 to win on timeout, got %v", payload)
+	}
+
+	game, err := redisClient.GetGame(gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.WinningTeam != 1 || !game.Players[1].Eliminated || !game.Players[3].Eliminated {
+		t.Fatalf("expected both team 2 players out and team 1 recorded as the winner, got %+v", game)
+	}
+}
+
+// A player leaving a lobby before it starts gives up their seat and the host can start without them
+func TestPlayerLeavesLobby(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedisClient(mr)
+
+	hub := createHub(redisClient, word.NewTestService())
+	go hub.Run()
+
+	gameID, _ := createTestLobby(t, redisClient, entities.GameTypePrivate, 3)
+	players := joinTestLobby(t, hub, gameID, 3)
+
+	if err := redisClient.AtomicLeaveLobby(gameID, "player-1"); err == nil || err.Error() != "host_cannot_leave" {
+		t.Fatalf("expected the host not to be able to leave, got %v", err)
+	}
+	if err := redisClient.AtomicLeaveLobby(gameID, "player-3"); err != nil {
+		t.Fatal(err)
+	}
+	left := expectMessage(t, players[0], "player_left")
+	payload, _ := left.Payload.(map[string]interface{})
+	if seats, _ := payload["players"].([]interface{}); payload["playerId"] != "player-3" || len(seats) != 2 {
+		t.Fatalf("expected player-3 to leave two seats behind, got %v", payload)
+	}
+
+	hub.actions <- &ClientActionRequest{Client: players[0], Action: &ClientAction{Action: "start_game", GameID: gameID}}
+	started := expectMessage(t, players[1], "game_started")
+	if seats, _ := started.Payload.(map[string]interface{})["players"].([]interface{}); len(seats) != 2 {
+		t.Fatalf("expected the game to start with two players, got %v", seats)
+	}
+}
+
+// Both players race from the same start word without taking turns, the first to the target wins
+func TestRaceToTargetWord(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedisClient(mr)
+	words := word.NewTestService()
+
+	hub := createHub(redisClient, words)
+	go hub.Run()
+
+	if distance := words.Distance("BOLD", "WORD"); distance != 3 {
+		t.Fatalf("expected BOLD to be 3 moves from WORD, got %d", distance)
+	}
+	target, par := words.PickTarget("COLD", entities.DifficultyMedium, 0)
+	if target != "WORD" || par != 2 {
+		t.Fatalf("expected the furthest word WORD as the target, got %s at %d", target, par)
+	}
+
+	gameID := redisclient.GenerateId()
+	joinCode := redisclient.GenerateGameCode()
+	err := redisClient.CreateGame(entities.Game{
+		ID:          gameID,
+		Type:        entities.GameTypeRace,
+		Status:      entities.GameStatusWaiting,
+		JoinCode:    joinCode,
+		Player1ID:   "player-1",
+		Player1Name: "Player 1",
+		CreatedAt:   time.Now().UnixMilli(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := redisClient.SetPrivateGameCode(joinCode, gameID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := redisClient.AtomicJoinPrivateGame(joinCode, "player-2", "Player 2", 0, "COLD", 0, target, par); err != nil {
+		t.Fatal(err)
+	}
+
+	player1 := newTestClient(hub, "player-1")
+	player2 := newTestClient(hub, "player-2")
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	started := expectMessage(t, player2, "game_started")
+	if payload, _ := started.Payload.(map[string]interface{}); payload["targetWord"] != "WORD" || payload["par"] != float64(2) {
+		t.Fatalf("expected a race to WORD in 2, got %v", payload)
+	}
+
+	// Player 2 heads the wrong way first, then player 1 moves twice in a row
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "BOLD"}}
+	progress := expectMessage(t, player1, "race_progress")
+	if payload, _ := progress.Payload.(map[string]interface{}); payload["playerId"] != "player-2" || payload["distance"] != float64(3) {
+		t.Fatalf("expected player-2 3 moves from the target, got %v", payload)
+	}
+
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "CORD"}}
+	expectMessage(t, player2, "race_progress")
+	hub.actions <- &ClientActionRequest{Client: player1, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "WORD"}}
+
+	ended := expectMessage(t, player2, "game_ended")
+	payload, _ := ended.Payload.(map[string]interface{})
+	moveCounts, _ := payload["moveCounts"].(map[string]interface{})
+	if payload["winnerId"] != "player-1" || payload["reason"] != "target_reached" || moveCounts["player-1"] != float64(2) {
+		t.Fatalf("expected player-1 to reach the target in 2 moves, got %v", payload)
+	}
+}
+
+// A practice game starts when its only player connects. A hint costs score, an undo takes back
+// a move and its points, and the game ends with the score when no unplayed word is left
+func TestPracticeGameHintAndUndo(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedisClient(mr)
+
+	hub := createHub(redisClient, word.NewTestService())
+	go hub.Run()
+
+	gameID := redisclient.GenerateId()
+	err := redisClient.CreateReadyGame(entities.Game{
+		ID:            gameID,
+		Type:          entities.GameTypePractice,
+		Status:        entities.GameStatusReady,
+		Player1ID:     "player-1",
+		Player1Name:   "Player 1",
+		CurrentWord:   "COLD",
+		CurrentTurnID: "player-1",
+		CreatedAt:     time.Now().UnixMilli(),
+		PracticeMode:  entities.PracticeModeDictionary,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	player := newTestClient(hub, "player-1")
+	hub.actions <- &ClientActionRequest{Client: player, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+	started := expectMessage(t, player, "game_started")
+	if payload, _ := started.Payload.(map[string]interface{}); payload["type"] != "practice" || payload["practiceMode"] != "dictionary" {
+		t.Fatalf("expected a practice game against the dictionary, got %v", payload)
+	}
+	if deadline, err := mr.ZScore("game:expire", gameID); err != nil || deadline < float64(time.Now().Add(time.Minute).UnixMilli()) {
+		t.Fatalf("expected a practice game against the dictionary to have an idle deadline and no clock, got %v (%v)", deadline, err)
+	}
+
+	hub.actions <- &ClientActionRequest{Client: player, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "CORD"}}
+	moved := expectMessage(t, player, "word_submitted")
+	if payload, _ := moved.Payload.(map[string]interface{}); payload["score"] != float64(entities.PracticeMovePoints) {
+		t.Fatalf("expected a score of %d, got %v", entities.PracticeMovePoints, payload)
+	}
+
+	// COLD has been played, so WORD is the only hint from CORD
+	hub.actions <- &ClientActionRequest{Client: player, Action: &ClientAction{Action: "request_hint", GameID: gameID}}
+	hint := expectMessage(t, player, "hint_given")
+	if payload, _ := hint.Payload.(map[string]interface{}); payload["word"] != "WORD" || payload["score"] != float64(entities.PracticeMovePoints-entities.PracticeHintCost) {
+		t.Fatalf("expected WORD as a hint for %d points, got %v", entities.PracticeHintCost, payload)
+	}
+
+	hub.actions <- &ClientActionRequest{Client: player, Action: &ClientAction{Action: "undo_move", GameID: gameID}}
+	undone := expectMessage(t, player, "move_undone")
+	if payload, _ := undone.Payload.(map[string]interface{}); payload["word"] != "CORD" || payload["currentWord"] != "COLD" || payload["score"] != float64(-entities.PracticeHintCost) {
+		t.Fatalf("expected CORD to be undone back to COLD, got %v", payload)
+	}
+
+	// BOLD only leads back to COLD, which has been played, but the move can still be taken back
+	hub.actions <- &ClientActionRequest{Client: player, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "BOLD"}}
+	stuck := expectMessage(t, player, "no_moves_left")
+	if payload, _ := stuck.Payload.(map[string]interface{}); payload["currentWord"] != "BOLD" {
+		t.Fatalf("expected no moves left from BOLD, got %v", payload)
+	}
+	hub.actions <- &ClientActionRequest{Client: player, Action: &ClientAction{Action: "undo_move", GameID: gameID}}
+	expectMessage(t, player, "move_undone")
+
+	// Playing into the dead end again, the player ends the game
+	hub.actions <- &ClientActionRequest{Client: player, Action: &ClientAction{Action: "submit_word", GameID: gameID, Word: "BOLD"}}
+	expectMessage(t, player, "no_moves_left")
+	hub.actions <- &ClientActionRequest{Client: player, Action: &ClientAction{Action: "forfeit", GameID: gameID}}
+	ended := expectMessage(t, player, "game_ended")
+	payload, _ := ended.Payload.(map[string]interface{})
+	if payload["reason"] != "no_moves_left" || payload["moveCount"] != float64(1) || payload["hintsUsed"] != float64(1) {
+		t.Fatalf("expected the game to end after 1 move with 1 hint used, got %v", payload)
+	}
+
+	game, err := redisClient.GetGame(gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Score != entities.PracticeMovePoints-entities.PracticeHintCost || game.WinnerID != "" {
+		t.Fatalf("expected a final score of %d and no winner, got %d and %q", entities.PracticeMovePoints-entities.PracticeHintCost, game.Score, game.WinnerID)
+	}
+	if mr.Exists("game:expire") {
+		t.Fatal("expected the idle deadline to be cleared once the game ended")
+	}
+}
+
+// Two players already waiting are paired once the longer wait has widened the window enough,
+// and games of another difficulty don't hide the match
+func TestWaitingPlayersMatchedAsWindowsWiden(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedisClient(mr)
+
+	hub := createHub(redisClient, word.NewTestService())
+	go hub.Run()
+
+	createWaitingGame := func(playerID string, rating int, difficulty entities.Difficulty, waited time.Duration) string {
+		gameID := redisclient.GenerateId()
+		createdAt := time.Now().Add(-waited).UnixMilli()
+		err := redisClient.CreateGame(entities.Game{
+			ID:            gameID,
+			Type:          entities.GameTypeOnline,
+			Status:        entities.GameStatusWaiting,
+			Player1ID:     playerID,
+			Player1Name:   playerID,
+			Player1Rating: rating,
+			Rated:         true,
+			Difficulty:    difficulty,
+			CreatedAt:     createdAt,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := redisClient.PushToMatchmakingQueue(gameID, rating, createdAt); err != nil {
+			t.Fatal(err)
+		}
+		return gameID
+	}
+
+	for i := 0; i < 30; i++ {
+		createWaitingGame(fmt.Sprintf("hard-%d", i), 1200, entities.DifficultyHard, 0)
+	}
+	longWaitID := createWaitingGame("player-1", 1000, entities.DifficultyMedium, time.Minute)
+	newcomerID := createWaitingGame("player-2", 1500, entities.DifficultyMedium, 0)
+
+	player2 := newTestClient(hub, "player-2")
+	hub.actions <- &ClientActionRequest{Client: player2, Action: &ClientAction{Action: "join_game", GameID: newcomerID}}
+	expectMessage(t, player2, "joined_game")
+
+	// 500 points apart is outside the newcomer's own window, but player 1 has waited a minute
+	newcomer, err := redisClient.GetGame(newcomerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matchedID, _, matched, err := redisClient.AtomicMatchWaitingGame(newcomer, "COLD", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matched || matchedID != longWaitID {
+		t.Fatalf("expected to be matched into %s, got %s (matched: %v)", longWaitID, matchedID, matched)
+	}
+
+	moved := expectMessage(t, player2, "matchmaking_matched")
+	if payload, _ := moved.Payload.(map[string]interface{}); payload["gameId"] != longWaitID {
+		t.Fatalf("expected to be sent to %s, got %v", longWaitID, payload)
+	}
+
+	game, err := redisClient.GetGame(longWaitID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Status != entities.GameStatusReady || game.Player2ID != "player-2" {
+		t.Fatalf("expected player-2 to have joined, got %s with %q", game.Status, game.Player2ID)
+	}
+	if mr.Exists("game:" + newcomerID) {
+		t.Fatal("expected the newcomer's own game to be deleted")
+	}
+	if queued, _ := mr.ZMembers("game:open:since"); len(queued) != 30 {
+		t.Fatalf("expected only the hard games left waiting, got %d", len(queued))
+	}
+}
+
+// Every dictionary shipped in the assets must give playable start words. Only 4 letter words are
+// shipped until the other lengths are built from a full word list, so those lengths aren't offered
+func TestShippedDictionariesValidateMoves(t *testing.T) {
+	wordService := word.NewService(filepath.Join("..", "shared", "assets"))
+
+	if !wordService.SupportsWordLength(entities.DefaultWordLength) {
+		t.Fatalf("expected a %d letter dictionary to be shipped", entities.DefaultWordLength)
+	}
+	for _, length := range []int{3, 4, 5, 6} {
+		if !wordService.SupportsWordLength(length) {
+			if _, err := wordService.PickStartWord(length, entities.DifficultyMedium, 1); !errors.Is(err, word.ErrUnsupportedWordLength) {
+				t.Fatalf("expected no %d letter start word without a dictionary, got %v", length, err)
+			}
+			continue
+		}
+
+		start, err := wordService.PickStartWord(length, entities.DifficultyMedium, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(start) != length {
+			t.Fatalf("expected a %d letter start word, got %s", length, start)
+		}
+		moves := wordService.Moves(start)
+		if len(moves) == 0 {
+			t.Fatalf("expected %s to have moves", start)
+		}
+
+		if !wordService.IsValidMove(length, start, moves[0]) {
+			t.Fatalf("expected %s to %s to be a valid move", start, moves[0])
+		}
+		if wordService.IsValidMove(length, start, start) {
+			t.Fatalf("expected replaying %s to be rejected", start)
+		}
+	}
+}
+
+func newTestRedisClient(mr *miniredis.Miniredis) *redisclient.RedisClient {
+	return redisclient.NewRedisClient(redisclient.RedisConfig{Addr: mr.Addr()})
+}
+
+// createTestGame creates a private game between player-1 and player-2 that is ready to start
+func createTestGame(t *testing.T, r *redisclient.RedisClient, startWord string) string {
+	t.Helper()
+
+	gameID := redisclient.GenerateId()
+	joinCode := redisclient.GenerateGameCode()
+
+	err := r.CreateGame(entities.Game{
+		ID:          gameID,
+		Type:        entities.GameTypePrivate,
+		Status:      entities.GameStatusWaiting,
+		JoinCode:    joinCode,
+		Player1ID:   "player-1",
+		Player1Name: "Player 1",
+		CreatedAt:   time.Now().UnixMilli(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetPrivateGameCode(joinCode, gameID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.AtomicJoinPrivateGame(joinCode, "player-2", "Player 2", 0, startWord, 0, "", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	return gameID
+}
+
+// createTestLobby creates a lobby hosted by player-1 starting from COLD, and fills it with
+// player-2 up to player-{size}. Returns the game ID and join code
+func createTestLobby(t *testing.T, r *redisclient.RedisClient, gameType entities.GameType, size int) (string, string) {
+	t.Helper()
+
+	gameID := redisclient.GenerateId()
+	joinCode := redisclient.GenerateGameCode()
+
+	host := entities.GamePlayer{ID: "player-1", Name: "Player 1"}
+	if gameType == entities.GameTypeTeam {
+		host.Team = 1
+	}
+	err := r.CreateGame(entities.Game{
+		ID:          gameID,
+		Type:        gameType,
+		Status:      entities.GameStatusWaiting,
+		JoinCode:    joinCode,
+		Player1ID:   "player-1",
+		Player1Name: "Player 1",
+		StartWord:   "COLD",
+		CreatedAt:   time.Now().UnixMilli(),
+		MaxPlayers:  size,
+		Players:     []entities.GamePlayer{host},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetPrivateGameCode(joinCode, gameID); err != nil {
+		t.Fatal(err)
+	}
+	for i := 2; i <= size; i++ {
+		playerID := fmt.Sprintf("player-%d", i)
+		if _, _, err := r.AtomicJoinPrivateGame(joinCode, playerID, playerID, 0, "CORD", 0, "", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return gameID, joinCode
+}
+
+// joinTestLobby connects player-1 up to player-{size} to a lobby
+func joinTestLobby(t *testing.T, hub *Hub, gameID string, size int) []*Client {
+	t.Helper()
+
+	players := make([]*Client, 0, size)
+	for i := 1; i <= size; i++ {
+		player := newTestClient(hub, fmt.Sprintf("player-%d", i))
+		hub.actions <- &ClientActionRequest{Client: player, Action: &ClientAction{Action: "join_game", GameID: gameID}}
+		expectMessage(t, player, "joined_game")
+		players = append(players, player)
+	}
+	return players
+}
+
+func newTestClient(hub *Hub, userID string) *Client {
+	client := &Client{
+		Hub:    hub,
+		Send:   make(chan []byte, 256),
+		UserID: userID,
+	}
+	hub.register <- client
+	return client
+}
+
+// expectMessage waits for the next message of the given type, skipping any others
+func expectMessage(t *testing.T, client *Client, msgType string) GameMessage {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case data := <-client.Send:
+			var msg GameMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("client %s received invalid message: %v", client.UserID, err)
+			}
+			if msg.Type == "error" {
+				t.Fatalf("client %s received error: %v", client.UserID, msg.Payload)
+			}
+			if msg.Type == msgType {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("client %s did not receive %s", client.UserID, msgType)
+		}
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"os"
 
 	"github.com/redis/go-redis/v9"
-	"github.com/simonPacker7/Delta/backend/game-service/word"
 	"github.com/simonPacker7/Delta/backend/shared/redisclient"
+	"github.com/simonPacker7/Delta/backend/shared/word"
 )
 
 func main() {
@@ -18,9 +18,9 @@ func main() {
 	}
 	redisURL := os.Getenv("REDIS_URL")
 	redisPassword := os.Getenv("REDIS_PASSWORD")
-	wordMapPath := os.Getenv("WORD_MAP_PATH")
-	if wordMapPath == "" {
-		wordMapPath = "/app/assets/4-WordMap.json"
+	wordAssetsDir := os.Getenv("WORD_ASSETS_DIR")
+	if wordAssetsDir == "" {
+		wordAssetsDir = "/app/assets"
 	}
 
 	rdb := redis.NewClient(&redis.Options{
@@ -36,10 +36,10 @@ func main() {
 		DB:       0,
 	})
 
-	// Initialize word service
-	wordService := word.NewService(wordMapPath)
+	// Initialize word service with a word map for each word length
+	wordService := word.NewService(wordAssetsDir)
 
-	hub := createHub(rdb, redisClient, wordService)
+	hub := createHub(redisClient, wordService)
 	go hub.Run()
 
 	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+)
+
+// handlePracticeSubmit plays an already validated word in a practice game. A word that leaves the player
+// with no unplayed word doesn't end the game, the no_moves_left event lets them undo it or end the game by forfeiting
+func (h *Hub) handlePracticeSubmit(client *Client, game entities.Game, word string) {
+	moves, err := h.unplayedMoves(game, word)
+	if err != nil {
+		log.Printf("Error getting played words: %v", err)
+		h.sendErrorToClient(client, "submit_failed", "server_error")
+		return
+	}
+
+	score, err := h.redisClient.AtomicSubmitPracticeWord(game.ID, client.UserID, game.Player1Name, word, len(moves) == 0)
+	if err != nil {
+		log.Printf("Error submitting practice word: %v", err)
+		h.sendErrorToClient(client, "submit_failed", err.Error())
+		return
+	}
+
+	log.Printf("Client %s submitted word '%s' for practice game %s, score: %d, moves left: %d", client.UserID, word, game.ID, score, len(moves))
+}
+
+// handleHint gives the player of a practice game a random legal, unplayed neighbour of the current word
+// in return for some of their score. The hint_given event is delivered through the game's event stream
+func (h *Hub) handleHint(client *Client, gameID string) {
+	if client.GameID != gameID || client.Spectating {
+		h.sendErrorToClient(client, "hint_failed", "not_in_game")
+		return
+	}
+
+	game, err := h.redisClient.GetGame(gameID)
+	if err != nil || game.ID == "" {
+		h.sendErrorToClient(client, "hint_failed", "game_not_found")
+		return
+	}
+	if game.Type != entities.GameTypePractice {
+		h.sendErrorToClient(client, "hint_failed", "not_a_practice_game")
+		return
+	}
+	if game.DictionaryVersion != "" && game.DictionaryVersion != h.wordService.Version() {
+		log.Printf("Game %s uses dictionary %s but this instance has %s", gameID, game.DictionaryVersion, h.wordService.Version())
+		h.sendErrorToClient(client, "hint_failed", "dictionary_mismatch")
+		return
+	}
+
+	moves, err := h.unplayedMoves(game, game.CurrentWord)
+	if err != nil {
+		log.Printf("Error getting played words for hint: %v", err)
+		h.sendErrorToClient(client, "hint_failed", "server_error")
+		return
+	}
+	if len(moves) == 0 {
+		h.sendErrorToClient(client, "hint_failed", "no_moves_left")
+		return
+	}
+	hint := moves[rand.Intn(len(moves))]
+
+	score, err := h.redisClient.AtomicPracticeHint(gameID, client.UserID, game.CurrentWord, hint)
+	if err != nil {
+		log.Printf("Error giving hint: %v", err)
+		h.sendErrorToClient(client, "hint_failed", err.Error())
+		return
+	}
+
+	log.Printf("Client %s was given a hint for practice game %s, score: %d", client.UserID, gameID, score)
+}
+
+// handleUndo takes back the last word played in a practice game. The move_undone event
+// is delivered through the game's event stream
+func (h *Hub) handleUndo(client *Client, gameID string) {
+	if client.GameID != gameID || client.Spectating {
+		h.sendErrorToClient(client, "undo_failed", "not_in_game")
+		return
+	}
+
+	currentWord, score, err := h.redisClient.AtomicUndoPracticeMove(gameID, client.UserID)
+	if err != nil {
+		log.Printf("Error undoing move: %v", err)
+		h.sendErrorToClient(client, "undo_failed", err.Error())
+		return
+	}
+
+	log.Printf("Client %s undid a move in practice game %s, back to '%s', score: %d", client.UserID, gameID, currentWord, score)
+}
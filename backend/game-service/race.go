@@ -0,0 +1,40 @@
+package main
+
+import (
+	"log"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+)
+
+// handleRaceSubmit moves a player on in a race game. Players move at the same time from their own
+// current word, so there is no turn check and words may be revisited. The race_progress event, and
+// game_ended when the target is reached, are delivered through the game's event stream
+func (h *Hub) handleRaceSubmit(client *Client, game entities.Game, word string) {
+	if game.DictionaryVersion != "" && game.DictionaryVersion != h.wordService.Version() {
+		log.Printf("Game %s uses dictionary %s but this instance has %s", game.ID, game.DictionaryVersion, h.wordService.Version())
+		h.sendErrorToClient(client, "submit_failed", "dictionary_mismatch")
+		return
+	}
+
+	fromWord := game.Player1Word
+	if client.UserID == game.Player2ID {
+		fromWord = game.Player2Word
+	}
+
+	if !h.wordService.IsValidMove(len(fromWord), fromWord, word) {
+		h.sendErrorToClient(client, "submit_failed", "invalid_move")
+		return
+	}
+
+	// Remaining distance lets both players see how close each other is
+	distance := h.wordService.Distance(word, game.TargetWord)
+
+	moveCount, finished, err := h.redisClient.AtomicSubmitRaceWord(game.ID, client.UserID, gamePlayerName(game, client.UserID), fromWord, word, distance)
+	if err != nil {
+		log.Printf("Error submitting race word: %v", err)
+		h.sendErrorToClient(client, "submit_failed", err.Error())
+		return
+	}
+
+	log.Printf("Client %s moved to '%s' in race %s (move %d, %d from target, finished: %v)", client.UserID, word, game.ID, moveCount, distance, finished)
+}
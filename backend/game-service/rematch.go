@@ -0,0 +1,126 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/redisclient"
+	"github.com/simonPacker7/Delta/backend/shared/word"
+)
+
+// handleRematch offers or accepts a rematch of a completed game. Once both players agree a new game
+// is created between them with the same settings, and a rematch_started event on the old game
+// tells both clients to join it
+func (h *Hub) handleRematch(client *Client, gameID string, accept bool) {
+	game, err := h.redisClient.GetGame(gameID)
+	if err != nil || game.ID == "" {
+		h.sendErrorToClient(client, "rematch_failed", "game_not_found")
+		return
+	}
+
+	opponentID := game.Player1ID
+	if client.UserID == game.Player1ID {
+		opponentID = game.Player2ID
+	}
+	_, opponentIsBot := entities.GetBotByID(opponentID)
+
+	newGameID := redisclient.GenerateId()
+	status, err := h.redisClient.AtomicRequestRematch(gameID, client.UserID, accept, opponentIsBot, newGameID)
+	if err != nil {
+		log.Printf("Error requesting rematch: %v", err)
+		h.sendErrorToClient(client, "rematch_failed", err.Error())
+		return
+	}
+
+	// The offer is delivered to the opponent through the game's event stream
+	if status != "accepted" {
+		log.Printf("Client %s offered a rematch of game %s", client.UserID, gameID)
+		return
+	}
+
+	rematch, err := h.newRematchGame(game, newGameID)
+	if err == nil {
+		err = h.redisClient.CreateReadyGame(rematch)
+	}
+	if err != nil {
+		log.Printf("Error creating rematch of game %s: %v", gameID, err)
+		// Free the claim so the rematch can be accepted again
+		if err := h.redisClient.AtomicReleaseRematch(gameID, newGameID); err != nil {
+			log.Printf("Error releasing rematch of game %s: %v", gameID, err)
+		}
+		h.sendErrorToClient(client, "rematch_failed", "server_error")
+		return
+	}
+
+	_, err = h.redisClient.AppendGameEvent(gameID, "rematch_started", map[string]interface{}{
+		"gameId":        newGameID,
+		"currentTurnId": rematch.CurrentTurnID,
+	})
+	if err != nil {
+		log.Printf("Error recording rematch of game %s: %v", gameID, err)
+	}
+
+	log.Printf("Game %s rematched as %s", gameID, newGameID)
+}
+
+// newRematchGame copies a completed game's players and settings into a new game,
+// swapping the players so the other one moves first. A rated rematch starts each player from
+// their rating after the finished game, which is already known before the game reaches Postgres
+func (h *Hub) newRematchGame(game entities.Game, newGameID string) (entities.Game, error) {
+	wordLength := game.WordLength
+	if wordLength == 0 {
+		wordLength = entities.DefaultWordLength
+	}
+	difficulty := game.Difficulty
+	if difficulty == "" {
+		difficulty = entities.DifficultyMedium
+	}
+
+	seed := word.NewSeed()
+	startWord := game.StartWord
+	if startWord == "" || startWord == entities.StartWordRandom {
+		var err error
+		if startWord, err = h.wordService.PickStartWord(wordLength, difficulty, seed); err != nil {
+			return entities.Game{}, err
+		}
+	}
+
+	// A bot is always connected, so only the player has to join
+	connectedCount := 0
+	if game.BotLevel != "" {
+		connectedCount = 1
+	}
+
+	player1Rating, player2Rating := game.Player2Rating, game.Player1Rating
+	if game.Rated {
+		player1Rating += game.Player2Delta
+		player2Rating += game.Player1Delta
+	}
+
+	return entities.Game{
+		ID:                newGameID,
+		Type:              game.Type,
+		Status:            entities.GameStatusReady,
+		Player1ID:         game.Player2ID,
+		Player1Name:       game.Player2Name,
+		Player2ID:         game.Player1ID,
+		Player2Name:       game.Player1Name,
+		Player1Rating:     player1Rating,
+		Player2Rating:     player2Rating,
+		Rated:             game.Rated,
+		CurrentWord:       startWord,
+		CurrentTurnID:     game.Player2ID,
+		ConnectedCount:    connectedCount,
+		TimeBaseMs:        game.TimeBaseMs,
+		TimeIncrementMs:   game.TimeIncrementMs,
+		WordLength:        game.WordLength,
+		AllowRepeats:      game.AllowRepeats,
+		StartWord:         game.StartWord,
+		CreatedAt:         time.Now().UnixMilli(),
+		DictionaryVersion: h.wordService.Version(),
+		Difficulty:        game.Difficulty,
+		StartWordSeed:     seed,
+		BotLevel:          game.BotLevel,
+	}, nil
+}
@@ -42,6 +42,8 @@ type Client struct {
 	Send   chan []byte // Buffered channel of outbound messages.
 	UserID string
 	GameID string
+	// Watching GameID rather than playing in it
+	Spectating bool
 }
 
 // Pumps messages from the websocket connection to the hub
@@ -0,0 +1,52 @@
+package entities
+
+// BotPlayer is a computer opponent. Each level is a seeded user with a fixed ID,
+// so bot games are stored and shown in history like any other game
+type BotPlayer struct {
+	ID    string
+	Name  string
+	Level Difficulty
+}
+
+var BotPlayers = []BotPlayer{
+	{ID: "00000000-0000-0000-0000-0000000b0001", Name: "Delta Bot (Easy)", Level: DifficultyEasy},
+	{ID: "00000000-0000-0000-0000-0000000b0002", Name: "Delta Bot (Medium)", Level: DifficultyMedium},
+	{ID: "00000000-0000-0000-0000-0000000b0003", Name: "Delta Bot (Hard)", Level: DifficultyHard},
+}
+
+// GetBotByLevel returns the bot player for a level
+func GetBotByLevel(level Difficulty) (BotPlayer, bool) {
+	for _, bot := range BotPlayers {
+		if bot.Level == level {
+			return bot, true
+		}
+	}
+	return BotPlayer{}, false
+}
+
+// BotLevelForRating picks a bot of about the same strength as a player
+func BotLevelForRating(rating int) Difficulty {
+	switch {
+	case rating < 1100:
+		return DifficultyEasy
+	case rating < 1400:
+		return DifficultyMedium
+	default:
+		return DifficultyHard
+	}
+}
+
+// GetBotByID returns the bot player with the given player ID
+func GetBotByID(playerID string) (BotPlayer, bool) {
+	for _, bot := range BotPlayers {
+		if bot.ID == playerID {
+			return bot, true
+		}
+	}
+	return BotPlayer{}, false
+}
+
+type CreateBotGameInput struct {
+	Level      Difficulty `json:"level"`      // how strong the bot plays
+	Difficulty Difficulty `json:"difficulty"` // the start word tier, medium if empty whatever the bot's level
+}
@@ -9,11 +9,45 @@ const (
 	GameStatusEnded   GameStatus = "completed"
 )
 
+// Default time control: each player has a 3 minute bank and gains nothing per move
+const (
+	DefaultTimeBaseMs      int64 = 3 * 60 * 1000
+	DefaultTimeIncrementMs int64 = 0
+)
+
+// Default word rules, used by online games and private games that don't override them
+const (
+	DefaultWordLength = 4
+	StartWordRandom   = "random"
+)
+
+// Private games can be lobbies for more than two players, started by the host once enough have joined
+const (
+	MinPlayers = 2
+	MaxPlayers = 6
+)
+
+// Team games are two teams of TeamSize, seated so the teams take alternate turns
+const TeamSize = 2
+
+// Difficulty tiers for start word selection
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyMedium Difficulty = "medium"
+	DifficultyHard   Difficulty = "hard"
+)
+
 type GameType string
 
 const (
-	GameTypeOnline  GameType = "online"
-	GameTypePrivate GameType = "private"
+	GameTypeOnline   GameType = "online"
+	GameTypePrivate  GameType = "private"
+	GameTypeBot      GameType = "bot"
+	GameTypeTeam     GameType = "team"     // private lobby of two teams of two
+	GameTypeRace     GameType = "race"     // private game where both players race to the same target word
+	GameTypePractice GameType = "practice" // single player game against the clock or the dictionary
 )
 
 type GameMove struct {
@@ -23,6 +57,15 @@ type GameMove struct {
 	Timestamp  int64  `json:"timestamp"`
 }
 
+// GamePlayer is a seat in a game for more than two players, in turn order
+type GamePlayer struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	TimeLeftMs int64  `json:"timeLeftMs"`
+	Eliminated bool   `json:"eliminated"`     // out of the game, their turns are skipped
+	Team       int    `json:"team,omitempty"` // 1 or 2 in team games
+}
+
 type Game struct {
 	ID             string     `json:"id" redis:"id"`
 	Type           GameType   `json:"type" redis:"type"`
@@ -32,14 +75,71 @@ type Game struct {
 	Player1Name    string     `json:"player1Name" redis:"player1_name"`
 	Player2ID      string     `json:"player2Id" redis:"player2_id"`
 	Player2Name    string     `json:"player2Name" redis:"player2_name"`
+	Player1Rating  int        `json:"player1Rating" redis:"player1_rating"`
+	Player2Rating  int        `json:"player2Rating" redis:"player2_rating"`
+	Rated          bool       `json:"rated" redis:"rated"` // only rated games change player ratings
 	CurrentWord    string     `json:"currentWord" redis:"current_word"`
 	CurrentTurnID  string     `json:"currentTurnId" redis:"current_turn_id"`
 	WinnerID       string     `json:"winnerId" redis:"winner_id"`
 	WinReason      string     `json:"winReason" redis:"win_reason"`
+	Player1Delta   int        `json:"player1RatingDelta" redis:"player1_rating_delta"`
+	Player2Delta   int        `json:"player2RatingDelta" redis:"player2_rating_delta"`
 	ConnectedCount int        `json:"connectedCount" redis:"connected_count"`
+	SpectatorCount int        `json:"spectatorCount" redis:"spectator_count"` // not counted in connected_count
 	CreatedAt      int64      `json:"createdAt" redis:"created_at"`
 	StartTime      int64      `json:"startTime" redis:"start_time"`
 	EndTime        int64      `json:"endTime" redis:"end_time"`
+	EventSeq       int64      `json:"eventSeq" redis:"event_seq"` // sequence number of the last game event
+	// Chess-clock time control, all in milliseconds
+	TimeBaseMs      int64 `json:"timeBaseMs" redis:"time_base_ms"`
+	TimeIncrementMs int64 `json:"timeIncrementMs" redis:"time_increment_ms"`
+	Player1TimeLeft int64 `json:"player1TimeLeftMs" redis:"player1_time_left_ms"`
+	Player2TimeLeft int64 `json:"player2TimeLeftMs" redis:"player2_time_left_ms"`
+	TurnStartedAt   int64 `json:"turnStartedAt" redis:"turn_started_at_ms"` // unix ms the current turn's clock started
+	// Word rules
+	WordLength   int    `json:"wordLength" redis:"word_length"`
+	AllowRepeats bool   `json:"allowRepeats" redis:"allow_repeats"` // played words may be played again
+	StartWord    string `json:"startWord" redis:"start_word"`       // fixed start word, or "random"
+	// Version of the dictionary the game was created with, moves are only validated against the same dictionary
+	DictionaryVersion string `json:"dictionaryVersion" redis:"dictionary_version"`
+	// The start word is picked from the difficulty tier using the seed, so it can be reproduced for debugging
+	Difficulty    Difficulty `json:"difficulty" redis:"difficulty"`
+	StartWordSeed int64      `json:"startWordSeed" redis:"start_word_seed"`
+	BotLevel      Difficulty `json:"botLevel,omitempty" redis:"bot_level"` // set when player 2 is a bot, bot games are never rated
+	// Rematch of a completed game
+	RematchOfferedBy string `json:"rematchOfferedBy,omitempty" redis:"rematch_offered_by"`
+	RematchGameID    string `json:"rematchGameId,omitempty" redis:"rematch_game_id"`
+	DrawOfferedBy    string `json:"drawOfferedBy,omitempty" redis:"draw_offered_by"` // pending draw offer, cleared when the other player moves
+	// Games for more than two players keep every seat, including its clock, in Players instead of the player1/player2 fields.
+	// Player 1 is the host. Players is stored as JSON in the players field and filled in by GetGame
+	MaxPlayers  int          `json:"maxPlayers,omitempty" redis:"max_players"`
+	Players     []GamePlayer `json:"players,omitempty" redis:"-"`
+	WinningTeam int          `json:"winningTeam,omitempty" redis:"winning_team"`
+	// Race games start both players on the same word and the first to reach the target wins.
+	// Par is the fewest moves it can be done in, each player moves from their own current word without taking turns
+	TargetWord       string `json:"targetWord,omitempty" redis:"target_word"`
+	Par              int    `json:"par,omitempty" redis:"par"`
+	Player1Word      string `json:"player1Word,omitempty" redis:"player1_word"`
+	Player2Word      string `json:"player2Word,omitempty" redis:"player2_word"`
+	Player1MoveCount int    `json:"player1MoveCount,omitempty" redis:"player1_move_count"`
+	Player2MoveCount int    `json:"player2MoveCount,omitempty" redis:"player2_move_count"`
+	// Practice games have only player 1, whose turn it always is. Hints and undone moves are taken off the score
+	PracticeMode PracticeMode `json:"practiceMode,omitempty" redis:"practice_mode"`
+	Score        int          `json:"score,omitempty" redis:"score"`
+	HintsUsed    int          `json:"hintsUsed,omitempty" redis:"hints_used"`
+}
+
+// GameSettings are chosen by the creator of a private game, zero values fall back to the defaults
+type GameSettings struct {
+	TimeBaseMs      int64      `json:"timeBaseMs"`
+	TimeIncrementMs int64      `json:"timeIncrementMs"`
+	WordLength      int        `json:"wordLength"`
+	AllowRepeats    bool       `json:"allowRepeats"`
+	StartWord       string     `json:"startWord"`  // a start word, or "random"
+	Difficulty      Difficulty `json:"difficulty"` // tier the random start word is picked from
+	MaxPlayers      int        `json:"maxPlayers"` // more than two makes the game a lobby the host starts
+	Teams           bool       `json:"teams"`      // two teams of two, implies four players
+	Race            bool       `json:"race"`       // race to a target word instead of taking turns
 }
 
 type FindGameResponse struct {
@@ -47,6 +147,10 @@ type FindGameResponse struct {
 	GameID string `json:"gameId"`
 }
 
+type CreatePrivateGameInput struct {
+	Settings GameSettings `json:"settings"`
+}
+
 type CreatePrivateGameResponse struct {
 	GameID   string `json:"gameId"`
 	JoinCode string `json:"joinCode"`
@@ -57,6 +161,34 @@ type JoinPrivateGameInput struct {
 }
 
 type JoinPrivateGameResponse struct {
-	Status string `json:"status"` // "matched" or "error"
+	Status string `json:"status"` // "matched", "waiting" for a multi-player lobby, or "error"
 	GameID string `json:"gameId"`
 }
+
+type GameHistoryEntry struct {
+	GameID          string   `json:"gameId"`
+	Type            GameType `json:"type"`
+	OpponentID      string   `json:"opponentId"`
+	OpponentName    string   `json:"opponentName"`
+	Result          string   `json:"result"` // "win", "loss" or "draw", empty for practice games
+	Rated           bool     `json:"rated"`
+	RatingDelta     int      `json:"ratingDelta"`
+	WinReason       string   `json:"winReason"`
+	StartWord       string   `json:"startWord"`
+	MoveCount       int      `json:"moveCount"`
+	DurationSeconds int64    `json:"durationSeconds"`
+	EndTime         int64    `json:"endTime"`
+	Score           int      `json:"score,omitempty"`         // practice games only
+	Par             int      `json:"par,omitempty"`           // race games only
+	RaceMoveCount   int      `json:"raceMoveCount,omitempty"` // race games only, the player's own moves to compare with par
+}
+
+type GameHistoryResponse struct {
+	Games      []GameHistoryEntry `json:"games"`
+	NextCursor string             `json:"nextCursor"` // empty when there are no more games
+}
+
+type GameMovesResponse struct {
+	GameID string     `json:"gameId"`
+	Moves  []GameMove `json:"moves"`
+}
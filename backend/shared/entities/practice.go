@@ -0,0 +1,21 @@
+package entities
+
+// PracticeMode is what a single player practises against
+type PracticeMode string
+
+const (
+	PracticeModeClock      PracticeMode = "clock"      // score as many words as possible before the clock runs out
+	PracticeModeDictionary PracticeMode = "dictionary" // untimed, play on until there is no unplayed word left
+)
+
+// Practice games score PracticeMovePoints for every word played. A hint costs PracticeHintCost
+// and undoing a move takes its points back, so neither can be used to build up a score
+const (
+	PracticeMovePoints = 10
+	PracticeHintCost   = 5
+)
+
+type CreatePracticeGameInput struct {
+	Mode       PracticeMode `json:"mode"`
+	Difficulty Difficulty   `json:"difficulty"`
+}
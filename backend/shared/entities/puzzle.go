@@ -0,0 +1,39 @@
+package entities
+
+// PuzzleDateFormat is the format of a daily puzzle's date, which is a UTC calendar day
+const PuzzleDateFormat = "2006-01-02"
+
+type DailyPuzzle struct {
+	Date       string        `json:"date"`
+	StartWord  string        `json:"startWord"`
+	TargetWord string        `json:"targetWord"`
+	Par        int           `json:"par"`              // fewest moves the puzzle can be solved in
+	Result     *PuzzleResult `json:"result,omitempty"` // the player's own result, once they have solved it
+}
+
+type SubmitPuzzleInput struct {
+	Date  string   `json:"date"`  // the puzzle being solved, today's if empty. Yesterday's is accepted too
+	Words []string `json:"words"` // every word from the start word to the target word
+}
+
+type PuzzleResult struct {
+	Date        string `json:"date"`
+	MoveCount   int    `json:"moveCount"`
+	Par         int    `json:"par"`
+	Rank        int    `json:"rank"`
+	SubmittedAt int64  `json:"submittedAt"`
+}
+
+type PuzzleLeaderboardEntry struct {
+	Rank        int    `json:"rank"`
+	PlayerID    string `json:"playerId"`
+	PlayerName  string `json:"playerName"`
+	MoveCount   int    `json:"moveCount"`
+	SubmittedAt int64  `json:"submittedAt"`
+}
+
+type PuzzleLeaderboardResponse struct {
+	Date    string                   `json:"date"`
+	Par     int                      `json:"par"`
+	Entries []PuzzleLeaderboardEntry `json:"entries"`
+}
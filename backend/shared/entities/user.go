@@ -12,7 +12,9 @@ type LoginUserInput struct {
 }
 
 type UserProfile struct {
-	Email string `json:"email" redis:"email"`
-	Name  string `json:"name" redis:"name"`
-	ID    string `json:"id" redis:"id"`
+	Email      string `json:"email" redis:"email"`
+	Name       string `json:"name" redis:"name"`
+	ID         string `json:"id" redis:"id"`
+	Rating     int    `json:"rating" redis:"rating"`
+	RatedGames int    `json:"ratedGames" redis:"rated_games"`
 }
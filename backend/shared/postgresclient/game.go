@@ -0,0 +1,334 @@
+package postgresclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+)
+
+// SaveCompletedGame writes a finished game, its players and its ordered move history
+// in a single transaction. Safe to retry: a game that has already been saved is left untouched
+func (p *PostgresClient) SaveCompletedGame(game entities.Game, moves []entities.GameMove) error {
+	ctx := context.Background()
+
+	tx, err := p.client.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Drawn games have no winner or loser
+	loserID := ""
+	switch game.WinnerID {
+	case game.Player1ID:
+		loserID = game.Player2ID
+	case game.Player2ID:
+		loserID = game.Player1ID
+	}
+
+	// Only practice games have a score, which may be 0
+	var score any
+	if game.Type == entities.GameTypePractice {
+		score = game.Score
+	}
+
+	// Only race games count each player's moves, which may be 0 for a player who never moved
+	var player1MoveCount, player2MoveCount any
+	if game.Type == entities.GameTypeRace {
+		player1MoveCount = game.Player1MoveCount
+		player2MoveCount = game.Player2MoveCount
+	}
+
+	// The start word is stored as a move but doesn't count towards the word count
+	wordCount := 0
+	for _, move := range moves {
+		if move.PlayerID != startMovePlayerID {
+			wordCount++
+		}
+	}
+
+	queryString := `insert into games (
+			id,
+			game_type,
+			created_at,
+			start_time,
+			end_time,
+			player_one_id,
+			player_two_id,
+			winner_id,
+			loser_id,
+			win_reason,
+			word_count,
+			rated,
+			player_one_rating_delta,
+			player_two_rating_delta,
+			bot_level,
+			winning_team,
+			par,
+			score,
+			player_one_move_count,
+			player_two_move_count
+		)
+		values (
+			$1,
+			$2,
+			$3,
+			$4,
+			$5,
+			$6,
+			$7,
+			$8,
+			$9,
+			$10,
+			$11,
+			$12,
+			$13,
+			$14,
+			$15,
+			$16,
+			$17,
+			$18,
+			$19,
+			$20
+		)
+		on conflict (id) do nothing
+		`
+	tag, err := tx.Exec(ctx, queryString,
+		game.ID,
+		string(game.Type),
+		time.UnixMilli(game.CreatedAt),
+		nullableTime(game.StartTime),
+		nullableTime(game.EndTime),
+		nullableID(game.Player1ID),
+		nullableID(game.Player2ID),
+		nullableID(game.WinnerID),
+		nullableID(loserID),
+		game.WinReason,
+		wordCount,
+		game.Rated,
+		game.Player1Delta,
+		game.Player2Delta,
+		nullableText(string(game.BotLevel)),
+		nullableInt(game.WinningTeam),
+		nullableInt(game.Par),
+		score,
+		player1MoveCount,
+		player2MoveCount,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Already persisted by an earlier attempt
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	// Games for more than two players record every seat, with the player's team in team games
+	for _, player := range game.Players {
+		_, err = tx.Exec(ctx, `insert into game_players (game_id, player_id, team) values ($1, $2, $3) on conflict do nothing`,
+			game.ID, player.ID, nullableInt(player.Team))
+		if err != nil {
+			return err
+		}
+	}
+	for _, playerID := range []string{game.Player1ID, game.Player2ID} {
+		if playerID == "" {
+			continue
+		}
+		_, err = tx.Exec(ctx, `insert into game_players (game_id, player_id) values ($1, $2) on conflict do nothing`,
+			game.ID, playerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rating changes are applied in the same transaction as the game insert,
+	// so a retried persist can never apply them twice. Games against bots never change ratings
+	if game.Rated && game.BotLevel == "" {
+		ratingChanges := map[string]int{game.Player1ID: game.Player1Delta, game.Player2ID: game.Player2Delta}
+		for playerID, delta := range ratingChanges {
+			_, err = tx.Exec(ctx, `update users set rating = rating + $2, rated_games = rated_games + 1 where id = $1`,
+				playerID, delta)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, move := range moves {
+		playerID := move.PlayerID
+		if playerID == startMovePlayerID {
+			playerID = ""
+		}
+		_, err = tx.Exec(ctx, `insert into game_moves (game_id, move_number, player_id, word, played_at) values ($1, $2, $3, $4, $5)`,
+			game.ID, i, nullableID(playerID), move.Word, nullableTime(move.Timestamp))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetGameHistory returns up to limit completed games for a player, newest first.
+// Game IDs are time-ordered, so the ID of the last game returned is used as the cursor for the next page
+func (p *PostgresClient) GetGameHistory(playerID string, cursor string, limit int) ([]entities.GameHistoryEntry, error) {
+	queryString := `select
+			g.id::text,
+			g.game_type::text,
+			g.win_reason::text,
+			g.word_count,
+			g.rated,
+			case when g.player_one_id = gp.player_id then g.player_one_rating_delta else g.player_two_rating_delta end,
+			g.start_time,
+			g.end_time,
+			coalesce(g.winner_id::text, ''),
+			coalesce(g.winning_team, 0),
+			coalesce(gp.team, 0),
+			coalesce(opponent.id::text, ''),
+			coalesce(opponent.username, ''),
+			coalesce(start_move.word, ''),
+			coalesce(g.score, 0),
+			coalesce(g.par, 0),
+			coalesce(case when g.player_one_id = gp.player_id then g.player_one_move_count else g.player_two_move_count end, 0)
+		from game_players gp
+		join games g on g.id = gp.game_id
+		left join users opponent on opponent.id = case
+			when g.player_one_id = gp.player_id then g.player_two_id
+			else g.player_one_id
+		end
+		left join game_moves start_move on start_move.game_id = g.id and start_move.move_number = 0
+		where gp.player_id = $1
+			and ($2::uuid is null or g.id < $2::uuid)
+		order by g.id desc
+		limit $3
+		`
+	rows, err := p.client.Query(context.Background(), queryString, playerID, nullableID(cursor), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]entities.GameHistoryEntry, 0, limit)
+	for rows.Next() {
+		var entry entities.GameHistoryEntry
+		var gameType string
+		var startTime, endTime *time.Time
+		var winnerID string
+		var winningTeam, team int
+
+		err := rows.Scan(&entry.GameID, &gameType, &entry.WinReason, &entry.MoveCount,
+			&entry.Rated, &entry.RatingDelta, &startTime, &endTime, &winnerID, &winningTeam, &team,
+			&entry.OpponentID, &entry.OpponentName, &entry.StartWord, &entry.Score,
+			&entry.Par, &entry.RaceMoveCount)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Type = entities.GameType(gameType)
+		switch {
+		case entry.Type == entities.GameTypePractice:
+			// Practice games are played alone and have no result
+		case winningTeam != 0 && winningTeam == team:
+			entry.Result = "win"
+		case winningTeam != 0:
+			entry.Result = "loss"
+		case winnerID == playerID:
+			entry.Result = "win"
+		case winnerID == "":
+			entry.Result = "draw"
+		default:
+			entry.Result = "loss"
+		}
+		if endTime != nil {
+			entry.EndTime = endTime.Unix()
+			if startTime != nil {
+				entry.DurationSeconds = int64(endTime.Sub(*startTime).Seconds())
+			}
+		}
+
+		history = append(history, entry)
+	}
+
+	return history, rows.Err()
+}
+
+// GetGameMoves returns the ordered move history of a completed game, including the start word
+func (p *PostgresClient) GetGameMoves(gameID string) ([]entities.GameMove, error) {
+	queryString := `select
+			coalesce(m.player_id::text, $2),
+			coalesce(u.username, 'start'),
+			m.word,
+			m.played_at
+		from game_moves m
+		left join users u on u.id = m.player_id
+		where m.game_id = $1
+		order by m.move_number
+		`
+	rows, err := p.client.Query(context.Background(), queryString, gameID, startMovePlayerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	moves := make([]entities.GameMove, 0)
+	for rows.Next() {
+		var move entities.GameMove
+		var playedAt *time.Time
+
+		if err := rows.Scan(&move.PlayerID, &move.PlayerName, &move.Word, &playedAt); err != nil {
+			return nil, err
+		}
+		if playedAt != nil {
+			move.Timestamp = playedAt.Unix()
+		}
+
+		moves = append(moves, move)
+	}
+
+	return moves, rows.Err()
+}
+
+// GetUserRating returns a player's current rating
+func (p *PostgresClient) GetUserRating(userID string) (int, error) {
+	var rating int
+	err := p.client.QueryRow(context.Background(), "select rating from users where id=$1", userID).Scan(&rating)
+	return rating, err
+}
+
+// Player ID recorded against the start word in the Redis moves list
+const startMovePlayerID = "0"
+
+// nullableID maps an empty ID to SQL null
+func nullableID(id string) any {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+// nullableText maps an empty string to SQL null
+func nullableText(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// nullableInt maps 0 to SQL null
+func nullableInt(value int) any {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+// nullableTime converts a unix timestamp in seconds to a time, mapping 0 to SQL null
+func nullableTime(seconds int64) any {
+	if seconds == 0 {
+		return nil
+	}
+	return time.Unix(seconds, 0)
+}
@@ -0,0 +1,111 @@
+package postgresclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+)
+
+// SavePuzzleResult records a player's solution to a daily puzzle.
+// Returns false if the player has already solved that day's puzzle, in which case their first result stands
+func (p *PostgresClient) SavePuzzleResult(date string, playerID string, words []string) (bool, error) {
+	wordsJSON, err := json.Marshal(words)
+	if err != nil {
+		return false, err
+	}
+
+	queryString := `insert into daily_puzzle_results (
+			puzzle_date,
+			player_id,
+			move_count,
+			words,
+			submitted_at
+		)
+		values (
+			$1::date,
+			$2,
+			$3,
+			$4,
+			$5
+		)
+		on conflict (puzzle_date, player_id) do nothing
+		`
+	tag, err := p.client.Exec(context.Background(), queryString,
+		date, playerID, len(words)-1, string(wordsJSON), time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetPuzzleResult returns a player's result for a daily puzzle with their rank on the leaderboard,
+// or nil if they haven't solved it
+func (p *PostgresClient) GetPuzzleResult(date string, playerID string) (*entities.PuzzleResult, error) {
+	queryString := `select
+			r.move_count,
+			r.submitted_at,
+			1 + (
+				select count(*) from daily_puzzle_results better
+				where better.puzzle_date = r.puzzle_date
+					and (better.move_count < r.move_count
+						or (better.move_count = r.move_count and better.submitted_at < r.submitted_at))
+			)
+		from daily_puzzle_results r
+		where r.puzzle_date = $1::date and r.player_id = $2
+		`
+	var result entities.PuzzleResult
+	var submittedAt time.Time
+	err := p.client.QueryRow(context.Background(), queryString, date, playerID).Scan(&result.MoveCount, &submittedAt, &result.Rank)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Date = date
+	result.SubmittedAt = submittedAt.Unix()
+	return &result, nil
+}
+
+// GetPuzzleLeaderboard returns the best limit results for a daily puzzle,
+// fewest moves first and the earliest solution first among equal move counts
+func (p *PostgresClient) GetPuzzleLeaderboard(date string, limit int) ([]entities.PuzzleLeaderboardEntry, error) {
+	queryString := `select
+			r.player_id::text,
+			u.username,
+			r.move_count,
+			r.submitted_at
+		from daily_puzzle_results r
+		join users u on u.id = r.player_id
+		where r.puzzle_date = $1::date
+		order by r.move_count, r.submitted_at
+		limit $2
+		`
+	rows, err := p.client.Query(context.Background(), queryString, date, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]entities.PuzzleLeaderboardEntry, 0, limit)
+	for rows.Next() {
+		var entry entities.PuzzleLeaderboardEntry
+		var submittedAt time.Time
+
+		if err := rows.Scan(&entry.PlayerID, &entry.PlayerName, &entry.MoveCount, &submittedAt); err != nil {
+			return nil, err
+		}
+		entry.Rank = len(entries) + 1
+		entry.SubmittedAt = submittedAt.Unix()
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -39,8 +39,8 @@ func (p *PostgresClient) CreateUser(input entities.RegisterUserInput) (string, e
 
 func (p *PostgresClient) GetUserProfile(email string) (entities.UserProfile, error) {
 	var profile entities.UserProfile
-	queryString := `select id, username, email from users where email=$1`
-	err := p.client.QueryRow(context.Background(), queryString, email).Scan(&profile.ID, &profile.Name, &profile.Email)
+	queryString := `select id, username, email, rating, rated_games from users where email=$1`
+	err := p.client.QueryRow(context.Background(), queryString, email).Scan(&profile.ID, &profile.Name, &profile.Email, &profile.Rating, &profile.RatedGames)
 	return profile, err
 }
 
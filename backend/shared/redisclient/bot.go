@@ -0,0 +1,90 @@
+package redisclient
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+)
+
+// ========== Bot Turn Operations ==========
+
+// botTurnSet holds games where it is a bot's turn, scored by the unix ms from which the bot may move.
+// The arbiter claims due turns from here, so each bot move is made by exactly one instance
+// whether or not any game-service instance is following the game
+const botTurnSet = "game:bot:turns"
+
+// How long a bot waits before playing, so its moves don't land instantly
+const botThinkTime = 1500 * time.Millisecond
+
+// How long a claimed bot turn is held before another arbiter may retry it
+const botTurnLease = 30 * time.Second
+
+// botFunction is prepended to any script that may hand the turn to a bot.
+// queueBotTurn(gameKey, gameId, turnId, now) clears any queued bot turn for the game
+// and queues a new one when turnId is the game's bot. Each bot level is played by one bot,
+// so the bot is found from the game's bot_level
+var botFunction = `
+local function queueBotTurn(gameKey, gameId, turnId, now)
+    redis.call('ZREM', '` + botTurnSet + `', gameId)
+    local botIds = {` + botIDsByLevel() + `}
+    local botLevel = redis.call('HGET', gameKey, 'bot_level')
+    if botLevel and botIds[botLevel] == turnId then
+        redis.call('ZADD', '` + botTurnSet + `', now + ` + strconv.FormatInt(botThinkTime.Milliseconds(), 10) + `, gameId)
+    end
+end
+`
+
+// botIDsByLevel renders the bot players as the body of a Lua table keyed by level
+func botIDsByLevel() string {
+	fields := ""
+	for _, bot := range entities.BotPlayers {
+		fields += "['" + string(bot.Level) + "'] = '" + bot.ID + "', "
+	}
+	return fields
+}
+
+// AtomicClaimBotTurns atomically claims bot turns that are due by pushing them back by the lease.
+// A claimed turn is cleared when the bot's move is submitted or the game ends,
+// so it only comes round again if the arbiter that claimed it failed to play
+var claimBotTurnsScript = `
+local botTurns = KEYS[1]
+local now = tonumber(ARGV[1])
+local limit = ARGV[2]
+local lease = tonumber(ARGV[3])
+
+local due = redis.call('ZRANGEBYSCORE', botTurns, 0, now, 'LIMIT', 0, limit)
+for i, gameId in ipairs(due) do
+    redis.call('ZADD', botTurns, now + lease, gameId)
+end
+return due
+`
+
+// AtomicClaimBotTurns returns the IDs of up to limit games where a bot is due to move
+func (r *RedisClient) AtomicClaimBotTurns(limit int) ([]string, error) {
+	result, err := r.client.Eval(ctx, claimBotTurnsScript, []string{botTurnSet},
+		time.Now().UnixMilli(), limit, botTurnLease.Milliseconds(),
+	).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := result.([]interface{})
+	if !ok {
+		return []string{}, nil
+	}
+
+	gameIDs := make([]string, 0, len(arr))
+	for _, item := range arr {
+		if gameID, ok := item.(string); ok {
+			gameIDs = append(gameIDs, gameID)
+		}
+	}
+
+	return gameIDs, nil
+}
+
+// RemoveBotTurn drops a queued bot turn for a game that is no longer active
+func (r *RedisClient) RemoveBotTurn(gameID string) error {
+	return r.client.ZRem(ctx, botTurnSet, gameID).Err()
+}
@@ -23,7 +23,6 @@ var ctx = context.Background()
 const userRecordPrefix = "user:"
 const gameSessionRecordPrefix = "game:session:"
 const gamePrivateSessionRecordPrefix = "game:private:session:"
-const matchmakingQueuePrefix = "game:open"
 const gameExpiredQueuePrefix = "game:expired:queue"
 
 func NewRedisClient(cfg RedisConfig) *RedisClient {
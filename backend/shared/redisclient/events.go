@@ -0,0 +1,151 @@
+package redisclient
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Every game event is written to a per-game stream (game:{id}:stream) by the same script
+// that changes the game. Entry IDs are "{seq}-0", where seq is the game's event_seq counter,
+// so the stream is ordered and can be replayed from any sequence number
+const gameEventStreamSuffix = ":stream"
+
+// gameEventFunction is prepended to any script that emits a game event.
+// appendGameEvent(gameKey, gameId, eventType, payloadJson) gives the event the next
+// sequence number for the game, adds it to the game's event stream and returns the sequence number
+var gameEventFunction = `
+local function appendGameEvent(gameKey, gameId, eventType, payloadJson)
+    local streamKey = gameKey .. ':stream'
+    local seq = redis.call('HINCRBY', gameKey, 'event_seq', 1)
+    local msg = '{"type":"' .. eventType .. '","gameId":"' .. gameId .. '","seq":' .. seq .. ',"payload":' .. payloadJson .. '}'
+
+    redis.call('XADD', streamKey, seq .. '-0', 'event', msg)
+    redis.call('EXPIRE', streamKey, 86400)
+
+    return seq
+end
+`
+
+var appendGameEventScript = gameEventFunction + `
+local gameKey = KEYS[1]
+local gameId = ARGV[1]
+local eventType = ARGV[2]
+local payloadJson = ARGV[3]
+
+if redis.call('EXISTS', gameKey) == 0 then
+    return -1
+end
+
+return appendGameEvent(gameKey, gameId, eventType, payloadJson)
+`
+
+// AppendGameEvent adds an event that isn't tied to a game state change to the game's event stream.
+// Returns the event's sequence number
+func (r *RedisClient) AppendGameEvent(gameID string, eventType string, payload interface{}) (int64, error) {
+	gameKey := gameKeyPrefix + gameID
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	seq, err := r.client.Eval(ctx, appendGameEventScript, []string{gameKey}, gameID, eventType, string(payloadJSON)).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if seq < 0 {
+		return 0, &AtomicOperationError{Message: "game_not_found"}
+	}
+
+	return seq, nil
+}
+
+// GameEvent is a single entry read from a game's event stream
+type GameEvent struct {
+	GameID string
+	Seq    int64
+	Data   []byte // encoded event, ready to send to clients
+}
+
+// ReadGameEvents blocks for up to block waiting for events after the given sequence number
+// in each game's stream. cursors maps game ID to the last sequence number already delivered
+func (r *RedisClient) ReadGameEvents(cursors map[string]int64, block time.Duration, count int64) ([]GameEvent, error) {
+	if len(cursors) == 0 {
+		time.Sleep(block)
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(cursors))
+	ids := make([]string, 0, len(cursors))
+	for gameID, seq := range cursors {
+		keys = append(keys, gameKeyPrefix+gameID+gameEventStreamSuffix)
+		ids = append(ids, streamID(seq))
+	}
+
+	streams, err := r.client.XRead(ctx, &redis.XReadArgs{
+		Streams: append(keys, ids...),
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]GameEvent, 0)
+	for _, stream := range streams {
+		gameID := strings.TrimSuffix(strings.TrimPrefix(stream.Stream, gameKeyPrefix), gameEventStreamSuffix)
+		for _, message := range stream.Messages {
+			events = append(events, toGameEvent(gameID, message))
+		}
+	}
+
+	return events, nil
+}
+
+// GetGameEventsSince returns every event after lastSeq, in order.
+// Returns ok=false when the stream can't cover the gap (expired, or lastSeq is ahead of the stream),
+// in which case the caller should fall back to sending the full game state
+func (r *RedisClient) GetGameEventsSince(gameID string, lastSeq int64) ([]GameEvent, bool, error) {
+	streamKey := gameKeyPrefix + gameID + gameEventStreamSuffix
+
+	length, err := r.client.XLen(ctx, streamKey).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if lastSeq < 0 || lastSeq > length {
+		return nil, false, nil
+	}
+
+	messages, err := r.client.XRange(ctx, streamKey, "("+streamID(lastSeq), "+").Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	events := make([]GameEvent, 0, len(messages))
+	for _, message := range messages {
+		events = append(events, toGameEvent(gameID, message))
+	}
+
+	return events, true, nil
+}
+
+func streamID(seq int64) string {
+	return strconv.FormatInt(seq, 10) + "-0"
+}
+
+func toGameEvent(gameID string, message redis.XMessage) GameEvent {
+	seq, _ := strconv.ParseInt(strings.TrimSuffix(message.ID, "-0"), 10, 64)
+	data, _ := message.Values["event"].(string)
+	return GameEvent{
+		GameID: gameID,
+		Seq:    seq,
+		Data:   []byte(data),
+	}
+}
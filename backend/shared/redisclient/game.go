@@ -2,6 +2,7 @@ package redisclient
 
 import (
 	"encoding/json"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -9,8 +10,10 @@ import (
 )
 
 const gameKeyPrefix = "game:"
-const matchmakingQueue = "game:open"
+const matchmakingQueue = "game:open:rated" // sorted set of waiting games scored by creator rating
 const privateGameCodePrefix = "game:code:"
+const waitingGamesSet = "game:waiting"       // sorted set of waiting games scored by created_at
+const matchmakingWaitSet = "game:open:since" // sorted set of the games in matchmakingQueue scored by created_at
 
 // CreateGame creates a new game in Redis with status "waiting"
 func (r *RedisClient) CreateGame(game entities.Game) error {
@@ -25,8 +28,22 @@ func (r *RedisClient) CreateGame(game entities.Game) error {
 		"player1_name", game.Player1Name,
 		"player2_id", game.Player2ID,
 		"player2_name", game.Player2Name,
+		"player1_rating", game.Player1Rating,
+		"player2_rating", game.Player2Rating,
+		"rated", game.Rated,
 		"current_word", game.CurrentWord,
 		"current_turn_id", game.CurrentTurnID,
+		"time_base_ms", game.TimeBaseMs,
+		"time_increment_ms", game.TimeIncrementMs,
+		"word_length", game.WordLength,
+		"allow_repeats", game.AllowRepeats,
+		"start_word", game.StartWord,
+		"dictionary_version", game.DictionaryVersion,
+		"difficulty", string(game.Difficulty),
+		"start_word_seed", game.StartWordSeed,
+		"bot_level", string(game.BotLevel),
+		"practice_mode", string(game.PracticeMode),
+		"max_players", game.MaxPlayers,
 		"connected_count", game.ConnectedCount,
 		"created_at", game.CreatedAt,
 	).Err()
@@ -35,20 +52,80 @@ func (r *RedisClient) CreateGame(game entities.Game) error {
 		return err
 	}
 
+	if len(game.Players) > 0 {
+		players, err := json.Marshal(game.Players)
+		if err != nil {
+			return err
+		}
+		if err := r.client.HSet(ctx, key, "players", players).Err(); err != nil {
+			return err
+		}
+	}
+
+	// Track waiting games so the arbiter can reap ones nobody joins
+	if game.Status == entities.GameStatusWaiting {
+		err = r.client.ZAdd(ctx, waitingGamesSet, redis.Z{
+			Score:  float64(game.CreatedAt),
+			Member: game.ID,
+		}).Err()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Set TTL of 24 hours
 	return r.client.Expire(ctx, key, 24*time.Hour).Err()
 }
 
+// CreateReadyGame creates a game whose players and start word are already decided, such as a bot game or a rematch.
+// The start word is recorded as for a joined game and the game starts once both players connect
+func (r *RedisClient) CreateReadyGame(game entities.Game) error {
+	err := r.CreateGame(game)
+	if err != nil {
+		return err
+	}
+
+	startMove, err := json.Marshal(MoveRecord{
+		PlayerID:   "0",
+		PlayerName: "start",
+		Word:       game.CurrentWord,
+		Timestamp:  time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	wordsKey := gameKeyPrefix + game.ID + ":words"
+	movesKey := gameKeyPrefix + game.ID + ":moves"
+
+	pipe := r.client.TxPipeline()
+	pipe.SAdd(ctx, wordsKey, game.CurrentWord)
+	pipe.Expire(ctx, wordsKey, 24*time.Hour)
+	pipe.RPush(ctx, movesKey, startMove)
+	pipe.Expire(ctx, movesKey, 24*time.Hour)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
 // GetGame retrieves a game from Redis by ID
 func (r *RedisClient) GetGame(gameID string) (entities.Game, error) {
 	key := gameKeyPrefix + gameID
 
+	fields := r.client.HGetAll(ctx, key)
+
 	var game entities.Game
-	err := r.client.HGetAll(ctx, key).Scan(&game)
+	err := fields.Scan(&game)
 	if err != nil {
 		return entities.Game{}, err
 	}
 
+	// Seats of a game for more than two players are stored as JSON
+	if players := fields.Val()["players"]; players != "" {
+		if err := json.Unmarshal([]byte(players), &game.Players); err != nil {
+			return entities.Game{}, err
+		}
+	}
+
 	return game, nil
 }
 
@@ -64,26 +141,45 @@ func (r *RedisClient) DoesGameExist(gameID string) (bool, error) {
 	return r.DoesRecordExist(key)
 }
 
-// PushToMatchmakingQueue adds a game ID to the matchmaking queue
-func (r *RedisClient) PushToMatchmakingQueue(gameID string) error {
-	return r.client.LPush(ctx, matchmakingQueue, gameID).Err()
+// PushToMatchmakingQueue adds a game ID to the matchmaking queue, scored by the creator's rating,
+// and records when it was created so the arbiter can rematch the games that have waited longest
+func (r *RedisClient) PushToMatchmakingQueue(gameID string, rating int, createdAt int64) error {
+	pipe := r.client.TxPipeline()
+	pipe.ZAdd(ctx, matchmakingQueue, redis.Z{
+		Score:  float64(rating),
+		Member: gameID,
+	})
+	pipe.ZAdd(ctx, matchmakingWaitSet, redis.Z{
+		Score:  float64(createdAt),
+		Member: gameID,
+	})
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
-// PopFromMatchmakingQueue removes and returns a game ID from the matchmaking queue
-func (r *RedisClient) PopFromMatchmakingQueue() (string, error) {
-	result, err := r.client.RPop(ctx, matchmakingQueue).Result()
-	if err != nil {
-		return "", err
-	}
-	return result, nil
+// RemoveFromMatchmaking removes a game from the matchmaking queue
+func (r *RedisClient) RemoveFromMatchmaking(gameID string) error {
+	pipe := r.client.TxPipeline()
+	pipe.ZRem(ctx, matchmakingQueue, gameID)
+	pipe.ZRem(ctx, matchmakingWaitSet, gameID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetMatchmakingGames returns up to limit games in the matchmaking queue, the longest waiting first
+func (r *RedisClient) GetMatchmakingGames(limit int) ([]string, error) {
+	return r.client.ZRange(ctx, matchmakingWaitSet, 0, int64(limit-1)).Result()
 }
 
 // AtomicCancelMatchmaking atomically removes a waiting game from matchmaking
-// Removes from queue, deletes game hash, and deletes join code if present
-var cancelMatchmakingScript = `
+// Removes from queue, deletes game hash, and deletes join code if present.
+// Players waiting in a multi-player lobby are sent a matchmaking_expired event
+var cancelMatchmakingScript = gameEventFunction + `
 local gameKey = KEYS[1]
 local queueKey = KEYS[2]
 local codeKey = KEYS[3]
+local waitingSet = KEYS[4]
+local sinceSet = KEYS[5]
 local gameId = ARGV[1]
 local playerId = ARGV[2]
 
@@ -104,13 +200,20 @@ if player1Id ~= playerId then
 end
 
 -- Remove from matchmaking queue
-redis.call('LREM', queueKey, 0, gameId)
+redis.call('ZREM', queueKey, gameId)
+redis.call('ZREM', sinceSet, gameId)
+redis.call('ZREM', waitingSet, gameId)
 
 -- Delete join code if it exists
 if codeKey ~= '' then
     redis.call('DEL', codeKey)
 end
 
+-- Record the event before the game hash (and its event counter) is deleted
+if (tonumber(redis.call('HGET', gameKey, 'max_players')) or 0) > 2 then
+    appendGameEvent(gameKey, gameId, 'matchmaking_expired', cjson.encode({playerId = player1Id}))
+end
+
 -- Delete the game hash
 redis.call('DEL', gameKey)
 
@@ -125,7 +228,7 @@ func (r *RedisClient) AtomicCancelMatchmaking(gameID string, playerID string, jo
 	}
 
 	result, err := r.client.Eval(ctx, cancelMatchmakingScript,
-		[]string{gameKey, matchmakingQueue, codeKey},
+		[]string{gameKey, matchmakingQueue, codeKey, waitingGamesSet, matchmakingWaitSet},
 		gameID, playerID,
 	).Result()
 
@@ -147,10 +250,13 @@ func (r *RedisClient) AtomicCancelMatchmaking(gameID string, playerID string, jo
 }
 
 // AtomicForfeitGame atomically ends a game due to forfeit
-// Sets the opponent as winner and publishes game_ended event
-var forfeitGameScript = `
+// Sets the opponent as winner and records the game_ended event.
+// In a game for more than two players only the forfeiting player is eliminated,
+// and forfeiting a practice game ends it with the score so far
+var forfeitGameScript = gameEventFunction + ratingDeltasFunction + twoPlayerFunction + clockFunction + playersFunction + practiceFunction + `
 local gameKey = KEYS[1]
 local expireSet = KEYS[2]
+local persistQueue = KEYS[3]
 local playerId = ARGV[1]
 local gameId = ARGV[2]
 
@@ -165,6 +271,22 @@ if status ~= 'active' then
     return {'', 'game_not_active'}
 end
 
+if isPractice(gameKey) then
+    if redis.call('HGET', gameKey, 'player1_id') ~= playerId then
+        return {'', 'not_in_game'}
+    end
+    endPracticeGame(gameKey, gameId, 'forfeit', expireSet, persistQueue)
+    return {'', ''}
+end
+
+if isMultiplayer(gameKey) then
+    local found, winnerId = eliminatePlayer(gameKey, gameId, playerId, 'forfeit', expireSet, persistQueue)
+    if not found then
+        return {'', 'not_in_game'}
+    end
+    return {winnerId, ''}
+end
+
 -- Verify player is in the game
 local player1Id = redis.call('HGET', gameKey, 'player1_id')
 local player2Id = redis.call('HGET', gameKey, 'player2_id')
@@ -179,31 +301,18 @@ if playerId == player1Id then
     winnerId = player2Id
 end
 
--- End the game
-local timeResult = redis.call('TIME')
-local endTime = tonumber(timeResult[1])
-redis.call('HSET', gameKey,
-    'status', 'completed',
-    'winner_id', winnerId,
-    'win_reason', 'forfeit',
-    'end_time', endTime
-)
-
--- Remove from expiration queue
-redis.call('ZREM', expireSet, gameId)
-
--- Publish game ended event
-local jsonMsg = '{"type":"game_ended","gameId":"' .. gameId .. '","payload":{"winnerId":"' .. winnerId .. '","reason":"forfeit"}}'
-redis.call('PUBLISH', gameKey, jsonMsg)
+endTwoPlayerGame(gameKey, gameId, winnerId, 'forfeit', expireSet, persistQueue)
 
 return {winnerId, ''}
 `
 
+// AtomicForfeitGame returns the winner ID, which is empty while a game for more than two players goes on
+// and for practice games
 func (r *RedisClient) AtomicForfeitGame(gameID string, playerID string) (string, error) {
 	gameKey := gameKeyPrefix + gameID
 
 	result, err := r.client.Eval(ctx, forfeitGameScript,
-		[]string{gameKey, gameExpireSet},
+		[]string{gameKey, gameExpireSet, gamePersistQueue},
 		playerID, gameID,
 	).Result()
 
@@ -226,10 +335,80 @@ func (r *RedisClient) AtomicForfeitGame(gameID string, playerID string) (string,
 	return winnerID, nil
 }
 
-// PublishGameEvent publishes an event to the game's pub/sub channel
-func (r *RedisClient) PublishGameEvent(gameID string, event string) error {
-	channel := gameKeyPrefix + gameID
-	return r.client.Publish(ctx, channel, event).Err()
+// AtomicEndGameNoMoves atomically ends a game because the player to move has no legal word left
+// The current word is checked so a game that has moved on since the dead end was detected is left alone.
+// In a game for more than two players nobody else can move either, so the player who played the word wins.
+// A practice game simply ends with its score
+var endGameNoMovesScript = gameEventFunction + ratingDeltasFunction + twoPlayerFunction + clockFunction + playersFunction + practiceFunction + `
+local gameKey = KEYS[1]
+local expireSet = KEYS[2]
+local persistQueue = KEYS[3]
+local playerId = ARGV[1]
+local gameId = ARGV[2]
+local currentWord = ARGV[3]
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return {'', 'game_not_found'}
+end
+
+if status ~= 'active' then
+    return {'', 'game_not_active'}
+end
+
+if redis.call('HGET', gameKey, 'current_turn_id') ~= playerId or redis.call('HGET', gameKey, 'current_word') ~= currentWord then
+    return {'', 'game_state_changed'}
+end
+
+if isPractice(gameKey) then
+    endPracticeGame(gameKey, gameId, 'no_moves_left', expireSet, persistQueue)
+    return {'', ''}
+end
+
+if isMultiplayer(gameKey) then
+    local lastMove = cjson.decode(redis.call('LINDEX', gameKey .. ':moves', -1))
+    endMultiplayerGame(gameKey, gameId, lastMove.playerId, 'no_moves_left', expireSet, persistQueue)
+    return {lastMove.playerId, ''}
+end
+
+-- The player without a move loses
+local player1Id = redis.call('HGET', gameKey, 'player1_id')
+local player2Id = redis.call('HGET', gameKey, 'player2_id')
+local winnerId = player1Id
+if playerId == player1Id then
+    winnerId = player2Id
+end
+
+endTwoPlayerGame(gameKey, gameId, winnerId, 'no_moves_left', expireSet, persistQueue)
+
+return {winnerId, ''}
+`
+
+func (r *RedisClient) AtomicEndGameNoMoves(gameID string, playerID string, currentWord string) (string, error) {
+	gameKey := gameKeyPrefix + gameID
+
+	result, err := r.client.Eval(ctx, endGameNoMovesScript,
+		[]string{gameKey, gameExpireSet, gamePersistQueue},
+		playerID, gameID, currentWord,
+	).Result()
+
+	if err != nil {
+		return "", err
+	}
+
+	arr, ok := result.([]interface{})
+	if !ok || len(arr) < 2 {
+		return "", &AtomicOperationError{Message: "unexpected_result"}
+	}
+
+	winnerID, _ := arr[0].(string)
+	errMsg, _ := arr[1].(string)
+
+	if errMsg != "" {
+		return "", &AtomicOperationError{Message: errMsg}
+	}
+
+	return winnerID, nil
 }
 
 // SetPrivateGameCode maps a join code to a game ID with TTL
@@ -253,9 +432,13 @@ func (r *RedisClient) DeletePrivateGameCode(joinCode string) error {
 
 // AtomicJoinPrivateGame atomically validates and joins a private game
 // Returns: gameID, player1ID, error
-// Uses Lua script to ensure only one player can join
-var joinPrivateGameScript = `
+// Uses Lua script to ensure only one player can join.
+// targetWord and par are only set for race games, and must have been picked for the game's start word.
+// A multi-player lobby takes players until it is full and stays waiting until the host starts it.
+// Team games seat players on alternate teams in the order they join, so the teams take alternate turns
+var joinPrivateGameScript = gameEventFunction + clockFunction + playersFunction + `
 local codeKey = KEYS[1]
+local waitingSet = KEYS[2]
 local gameId = redis.call('GET', codeKey)
 if not gameId then
     return {'', '', 'invalid_code'}
@@ -272,39 +455,87 @@ local player1Id = redis.call('HGET', gameKey, 'player1_id')
 local player2Id = ARGV[1]
 local player2Name = ARGV[2]
 local startWord = ARGV[3]
+local player2Rating = ARGV[4]
+local startWordSeed = ARGV[5]
+local targetWord = ARGV[6]
+local par = ARGV[7]
 
 if player1Id == player2Id then
     return {'', '', 'cannot_join_own_game'}
 end
 
+if isMultiplayer(gameKey) then
+    local players = loadPlayers(gameKey)
+    if findPlayer(players, player2Id) then
+        return {gameId, player1Id, ''}
+    end
+    if #players >= tonumber(redis.call('HGET', gameKey, 'max_players')) then
+        return {'', '', 'game_full'}
+    end
+
+    local seat = {id = player2Id, name = player2Name, timeLeftMs = 0, eliminated = false}
+    if redis.call('HGET', gameKey, 'type') == 'team' then
+        seat.team = #players % 2 + 1
+    end
+    table.insert(players, seat)
+    savePlayers(gameKey, players)
+    appendGameEvent(gameKey, gameId, 'player_joined', cjson.encode({
+        playerId = player2Id,
+        playerName = player2Name,
+        players = players
+    }))
+    return {gameId, player1Id, ''}
+end
+
+-- A start word chosen by the creator takes priority over the random one
+local startWordSetting = redis.call('HGET', gameKey, 'start_word')
+if startWordSetting and startWordSetting ~= '' and startWordSetting ~= 'random' then
+    startWord = startWordSetting
+end
+
 -- Atomically update game and delete code
 redis.call('HSET', gameKey, 
     'player2_id', player2Id,
     'player2_name', player2Name,
+    'player2_rating', player2Rating,
     'status', 'ready',
     'current_word', startWord,
-    'current_turn_id', player1Id
+    'current_turn_id', player1Id,
+    'start_word_seed', startWordSeed
 )
 
+-- Race games start both players on the start word, heading for the target
+if targetWord ~= '' then
+    redis.call('HSET', gameKey,
+        'target_word', targetWord,
+        'par', par,
+        'player1_word', startWord,
+        'player2_word', startWord,
+        'player1_move_count', 0,
+        'player2_move_count', 0
+    )
+end
+
 -- Initialize played words set with starting word
 redis.call('SADD', wordsKey, startWord)
 redis.call('EXPIRE', wordsKey, 86400)
 
 -- Initialize moves list with starting word (no player for initial word)
 local movesKey = gameKey .. ':moves'
-local startMove = cjson.encode({playerId = '0', playerName = 'start', word = startWord, timestamp = redis.call('TIME')[1]})
+local startMove = cjson.encode({playerId = '0', playerName = 'start', word = startWord, timestamp = tonumber(redis.call('TIME')[1])})
 redis.call('RPUSH', movesKey, startMove)
 redis.call('EXPIRE', movesKey, 86400)
 
 redis.call('DEL', codeKey)
+redis.call('ZREM', waitingSet, gameId)
 
 return {gameId, player1Id, ''}
 `
 
-func (r *RedisClient) AtomicJoinPrivateGame(joinCode string, player2ID string, player2Name string, startWord string) (string, string, error) {
+func (r *RedisClient) AtomicJoinPrivateGame(joinCode string, player2ID string, player2Name string, player2Rating int, startWord string, startWordSeed int64, targetWord string, par int) (string, string, error) {
 	codeKey := privateGameCodePrefix + joinCode
 
-	result, err := r.client.Eval(ctx, joinPrivateGameScript, []string{codeKey}, player2ID, player2Name, startWord).Result()
+	result, err := r.client.Eval(ctx, joinPrivateGameScript, []string{codeKey, waitingGamesSet}, player2ID, player2Name, startWord, player2Rating, startWordSeed, targetWord, par).Result()
 	if err != nil {
 		return "", "", err
 	}
@@ -326,62 +557,174 @@ func (r *RedisClient) AtomicJoinPrivateGame(joinCode string, player2ID string, p
 	return gameID, player1ID, nil
 }
 
-// AtomicPopAndJoinGame atomically pops from queue and joins if valid
+// AtomicPopAndJoinGame atomically finds the closest rated waiting game of the same difficulty and joins it.
+// A waiting game accepts opponents within a rating window that starts at matchmakingBaseWindow
+// and widens by matchmakingWindowGrowth per second waited, up to matchmakingMaxWindow.
+// When the searcher is already waiting in matchmaking (searcherGameId is set) the window follows whichever
+// of the two players has waited longer, and on a match the searcher's own game is deleted and a
+// matchmaking_matched event tells them which game to join instead
 // Returns: gameID, player1ID, matched (bool), error
-var popAndJoinGameScript = `
+var popAndJoinGameScript = gameEventFunction + `
 local queueKey = KEYS[1]
+local waitingSet = KEYS[2]
+local sinceSet = KEYS[3]
 local playerId = ARGV[1]
 local playerName = ARGV[2]
 local startWord = ARGV[3]
-local maxAttempts = 10
+local playerRating = tonumber(ARGV[4])
+local baseWindow = tonumber(ARGV[5])
+local windowGrowth = tonumber(ARGV[6])
+local maxWindow = tonumber(ARGV[7])
+local difficulty = ARGV[8]
+local startWordSeed = ARGV[9]
+local searcherGameId = ARGV[10]
+local pageSize = 25
+
+local timeResult = redis.call('TIME')
+local nowMs = tonumber(timeResult[1]) * 1000 + math.floor(tonumber(timeResult[2]) / 1000)
+
+local function leaveMatchmaking(gameId)
+    redis.call('ZREM', queueKey, gameId)
+    redis.call('ZREM', sinceSet, gameId)
+    redis.call('ZREM', waitingSet, gameId)
+end
 
-for i = 1, maxAttempts do
-    local gameId = redis.call('RPOP', queueKey)
-    if not gameId then
+local searcherWaited = 0
+if searcherGameId ~= '' then
+    local searcherKey = 'game:' .. searcherGameId
+    if redis.call('HGET', searcherKey, 'status') ~= 'waiting' then
+        -- Matched, cancelled or expired since it was read
+        redis.call('ZREM', queueKey, searcherGameId)
+        redis.call('ZREM', sinceSet, searcherGameId)
         return {'', '', false}
     end
+    local createdAt = tonumber(redis.call('HGET', searcherKey, 'created_at')) or nowMs
+    searcherWaited = math.max(0, (nowMs - createdAt) / 1000)
+end
 
-    local gameKey = 'game:' .. gameId
-    local wordsKey = gameKey .. ':words'
-    local status = redis.call('HGET', gameKey, 'status')
-    local player1Id = redis.call('HGET', gameKey, 'player1_id')
+local bestId = nil
+local bestPlayer1Id = nil
+local bestDiff = nil
+
+-- Returns whether candidates further from the player's rating are still worth reading,
+-- and how many stale entries were removed from the page
+local function consider(candidates)
+    local removed = 0
+    for i = 1, #candidates, 2 do
+        local gameId = candidates[i]
+        local gameRating = tonumber(candidates[i + 1])
+        local diff = math.abs(gameRating - playerRating)
+        if bestDiff ~= nil and diff >= bestDiff then
+            return false, removed
+        end
+
+        local gameKey = 'game:' .. gameId
+        local status = redis.call('HGET', gameKey, 'status')
+        local player1Id = redis.call('HGET', gameKey, 'player1_id')
 
-    -- Skip invalid games
-    if status == 'waiting' and player1Id and player1Id ~= '' then
-        -- Don't match with self - put back and return not found
-        if player1Id == playerId then
-            redis.call('LPUSH', queueKey, gameId)
-            return {'', '', false}
+        if status ~= 'waiting' or not player1Id or player1Id == '' then
+            -- Stale entry, game was cancelled or has expired
+            redis.call('ZREM', queueKey, gameId)
+            redis.call('ZREM', sinceSet, gameId)
+            removed = removed + 1
+        elseif player1Id ~= playerId and redis.call('HGET', gameKey, 'difficulty') == difficulty then
+            -- Don't match with self, only match games of the same difficulty
+            local createdAt = tonumber(redis.call('HGET', gameKey, 'created_at')) or nowMs
+            local waitedSeconds = math.max(0, (nowMs - createdAt) / 1000, searcherWaited)
+            local window = math.min(maxWindow, baseWindow + windowGrowth * waitedSeconds)
+
+            if diff <= window then
+                bestId = gameId
+                bestPlayer1Id = player1Id
+                bestDiff = diff
+            end
         end
+    end
+    return true, removed
+end
 
-        -- Valid game - join it
-        redis.call('HSET', gameKey,
-            'player2_id', playerId,
-            'player2_name', playerName,
-            'status', 'ready',
-            'current_word', startWord,
-            'current_turn_id', player1Id
-        )
-        
-        -- Initialize played words set with starting word
-        redis.call('SADD', wordsKey, startWord)
-        redis.call('EXPIRE', wordsKey, 86400)
-        
-        -- Initialize moves list with starting word (no player for initial word)
-        local movesKey = gameKey .. ':moves'
-        local startMove = cjson.encode({playerId = '0', playerName = 'start', word = startWord, timestamp = redis.call('TIME')[1]})
-        redis.call('RPUSH', movesKey, startMove)
-        redis.call('EXPIRE', movesKey, 86400)
-        
-        return {gameId, player1Id, true}
+-- Page outwards from the player's rating, so stale entries and games of other
+-- difficulties can't hide a match further away
+local function search(command, from, to)
+    local offset = 0
+    while true do
+        local candidates = redis.call(command, queueKey, from, to, 'WITHSCORES', 'LIMIT', offset, pageSize)
+        local more, removed = consider(candidates)
+        if not more or #candidates < pageSize * 2 then
+            return
+        end
+        offset = offset + pageSize - removed
     end
 end
 
-return {'', '', false}
+-- Closest ratings above and below the player
+search('ZRANGEBYSCORE', playerRating, playerRating + maxWindow)
+search('ZREVRANGEBYSCORE', playerRating, playerRating - maxWindow)
+
+if not bestId then
+    return {'', '', false}
+end
+
+local gameKey = 'game:' .. bestId
+local wordsKey = gameKey .. ':words'
+leaveMatchmaking(bestId)
+
+-- Valid game - join it
+redis.call('HSET', gameKey,
+    'player2_id', playerId,
+    'player2_name', playerName,
+    'player2_rating', playerRating,
+    'status', 'ready',
+    'current_word', startWord,
+    'current_turn_id', bestPlayer1Id,
+    'start_word_seed', startWordSeed
+)
+
+-- Initialize played words set with starting word
+redis.call('SADD', wordsKey, startWord)
+redis.call('EXPIRE', wordsKey, 86400)
+
+-- Initialize moves list with starting word (no player for initial word)
+local movesKey = gameKey .. ':moves'
+local startMove = cjson.encode({playerId = '0', playerName = 'start', word = startWord, timestamp = tonumber(redis.call('TIME')[1])})
+redis.call('RPUSH', movesKey, startMove)
+redis.call('EXPIRE', movesKey, 86400)
+
+-- The searcher's own game is no longer needed. Record the event before the game hash (and its event counter) is deleted
+if searcherGameId ~= '' then
+    local searcherKey = 'game:' .. searcherGameId
+    leaveMatchmaking(searcherGameId)
+    appendGameEvent(searcherKey, searcherGameId, 'matchmaking_matched', cjson.encode({gameId = bestId}))
+    redis.call('DEL', searcherKey)
+end
+
+return {bestId, bestPlayer1Id, true}
 `
 
-func (r *RedisClient) AtomicPopAndJoinGame(playerID string, playerName string, startWord string) (string, string, bool, error) {
-	result, err := r.client.Eval(ctx, popAndJoinGameScript, []string{matchmakingQueue}, playerID, playerName, startWord).Result()
+// Rating window for matchmaking, in rating points
+const (
+	matchmakingBaseWindow   = 100
+	matchmakingWindowGrowth = 10 // per second waited
+	matchmakingMaxWindow    = 800
+)
+
+func (r *RedisClient) AtomicPopAndJoinGame(playerID string, playerName string, playerRating int, difficulty entities.Difficulty, startWord string, startWordSeed int64) (string, string, bool, error) {
+	return r.popAndJoinGame(playerID, playerName, playerRating, difficulty, startWord, startWordSeed, "")
+}
+
+// AtomicMatchWaitingGame runs matchmaking again for the creator of a waiting online game, whose window
+// has been widening while they wait. On a match the creator joins the other game and their own is deleted.
+// Returns: the game the creator joined, its player 1, matched (bool), error
+func (r *RedisClient) AtomicMatchWaitingGame(game entities.Game, startWord string, startWordSeed int64) (string, string, bool, error) {
+	return r.popAndJoinGame(game.Player1ID, game.Player1Name, game.Player1Rating, game.Difficulty, startWord, startWordSeed, game.ID)
+}
+
+func (r *RedisClient) popAndJoinGame(playerID string, playerName string, playerRating int, difficulty entities.Difficulty, startWord string, startWordSeed int64, searcherGameID string) (string, string, bool, error) {
+	result, err := r.client.Eval(ctx, popAndJoinGameScript, []string{matchmakingQueue, waitingGamesSet, matchmakingWaitSet},
+		playerID, playerName, startWord, playerRating,
+		matchmakingBaseWindow, matchmakingWindowGrowth, matchmakingMaxWindow,
+		string(difficulty), startWordSeed, searcherGameID,
+	).Result()
 	if err != nil {
 		return "", "", false, err
 	}
@@ -401,6 +744,231 @@ func (r *RedisClient) AtomicPopAndJoinGame(playerID string, playerName string, s
 	return gameID, player1ID, matched, nil
 }
 
+// GetMatchmakingGamesOlderThan returns up to limit games that have been in the matchmaking queue for longer than age,
+// the longest waiting first. Private games and lobbies never enter matchmaking, so they are never returned
+func (r *RedisClient) GetMatchmakingGamesOlderThan(age time.Duration, limit int) ([]string, error) {
+	cutoff := time.Now().Add(-age).UnixMilli()
+	return r.client.ZRangeByScore(ctx, matchmakingWaitSet, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(cutoff, 10),
+		Count: int64(limit),
+	}).Result()
+}
+
+// AtomicJoinBotToGame atomically fills a waiting online game's empty seat with a bot.
+// The game is taken out of matchmaking and set up exactly as if the bot had joined, but is no longer rated
+var joinBotToGameScript = `
+local gameKey = KEYS[1]
+local queueKey = KEYS[2]
+local waitingSet = KEYS[3]
+local sinceSet = KEYS[4]
+local gameId = ARGV[1]
+local botId = ARGV[2]
+local botName = ARGV[3]
+local botLevel = ARGV[4]
+local startWord = ARGV[5]
+local startWordSeed = ARGV[6]
+
+if redis.call('HGET', gameKey, 'status') ~= 'waiting' or redis.call('HGET', gameKey, 'type') ~= 'online' then
+    return 'game_not_available'
+end
+
+local player1Id = redis.call('HGET', gameKey, 'player1_id')
+
+redis.call('ZREM', queueKey, gameId)
+redis.call('ZREM', sinceSet, gameId)
+redis.call('ZREM', waitingSet, gameId)
+
+redis.call('HSET', gameKey,
+    'player2_id', botId,
+    'player2_name', botName,
+    'status', 'ready',
+    'rated', 0,
+    'bot_level', botLevel,
+    'current_word', startWord,
+    'current_turn_id', player1Id,
+    'start_word_seed', startWordSeed
+)
+
+-- Initialize played words set with starting word
+local wordsKey = gameKey .. ':words'
+redis.call('SADD', wordsKey, startWord)
+redis.call('EXPIRE', wordsKey, 86400)
+
+-- Initialize moves list with starting word (no player for initial word)
+local movesKey = gameKey .. ':moves'
+local startMove = cjson.encode({playerId = '0', playerName = 'start', word = startWord, timestamp = tonumber(redis.call('TIME')[1])})
+redis.call('RPUSH', movesKey, startMove)
+redis.call('EXPIRE', movesKey, 86400)
+
+return ''
+`
+
+func (r *RedisClient) AtomicJoinBotToGame(gameID string, bot entities.BotPlayer, startWord string, startWordSeed int64) error {
+	gameKey := gameKeyPrefix + gameID
+
+	result, err := r.client.Eval(ctx, joinBotToGameScript, []string{gameKey, matchmakingQueue, waitingGamesSet, matchmakingWaitSet},
+		gameID, bot.ID, bot.Name, string(bot.Level), startWord, startWordSeed,
+	).Result()
+	if err != nil {
+		return err
+	}
+
+	if errMsg, _ := result.(string); errMsg != "" {
+		return &AtomicOperationError{Message: errMsg}
+	}
+
+	return nil
+}
+
+// AtomicExpireWaitingGames atomically removes waiting games created before the cutoff.
+// Each game is taken out of the matchmaking queue and join code index, deleted,
+// and a matchmaking_expired event is recorded so the creator can be told
+var expireWaitingGamesScript = gameEventFunction + `
+local waitingSet = KEYS[1]
+local queueKey = KEYS[2]
+local sinceSet = KEYS[3]
+local cutoff = ARGV[1]
+local limit = ARGV[2]
+
+local stale = redis.call('ZRANGEBYSCORE', waitingSet, 0, cutoff, 'LIMIT', 0, limit)
+local results = {}
+
+for i, gameId in ipairs(stale) do
+    local gameKey = 'game:' .. gameId
+    local status = redis.call('HGET', gameKey, 'status')
+
+    -- Games that have since been matched are left alone
+    if status == 'waiting' then
+        local player1Id = redis.call('HGET', gameKey, 'player1_id')
+        local joinCode = redis.call('HGET', gameKey, 'join_code')
+
+        -- Record the event before the game hash (and its event counter) is deleted
+        appendGameEvent(gameKey, gameId, 'matchmaking_expired', cjson.encode({playerId = player1Id}))
+
+        redis.call('ZREM', queueKey, gameId)
+        redis.call('ZREM', sinceSet, gameId)
+        if joinCode and joinCode ~= '' then
+            redis.call('DEL', 'game:code:' .. joinCode)
+        end
+        redis.call('DEL', gameKey)
+
+        table.insert(results, {gameId, player1Id})
+    end
+
+    redis.call('ZREM', waitingSet, gameId)
+end
+
+return results
+`
+
+// ExpiredWaitingGame represents a waiting game that was removed before anyone joined
+type ExpiredWaitingGame struct {
+	GameID    string
+	Player1ID string
+}
+
+func (r *RedisClient) AtomicExpireWaitingGames(maxAge time.Duration, limit int) ([]ExpiredWaitingGame, error) {
+	cutoff := time.Now().Add(-maxAge).UnixMilli()
+	result, err := r.client.Eval(ctx, expireWaitingGamesScript, []string{waitingGamesSet, matchmakingQueue, matchmakingWaitSet}, cutoff, limit).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := result.([]interface{})
+	if !ok {
+		return []ExpiredWaitingGame{}, nil
+	}
+
+	results := make([]ExpiredWaitingGame, 0, len(arr))
+	for _, item := range arr {
+		if pair, ok := item.([]interface{}); ok && len(pair) == 2 {
+			gameID, _ := pair[0].(string)
+			player1ID, _ := pair[1].(string)
+			results = append(results, ExpiredWaitingGame{
+				GameID:    gameID,
+				Player1ID: player1ID,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// ratingDeltasFunction is prepended to any script that ends a game.
+// ratingDeltas(gameKey, player1Score) returns the Elo rating change for each player,
+// where player1Score is 1 for a player 1 win, 0.5 for a draw and 0 for a loss.
+// Changes are zero-sum and unrated games always return 0, 0
+var ratingDeltasFunction = `
+local function ratingDeltas(gameKey, player1Score)
+    if redis.call('HGET', gameKey, 'rated') ~= '1' then
+        return 0, 0
+    end
+    local botLevel = redis.call('HGET', gameKey, 'bot_level')
+    if botLevel and botLevel ~= '' then
+        return 0, 0
+    end
+
+    local kFactor = ` + strconv.Itoa(ratingKFactor) + `
+    local player1Rating = tonumber(redis.call('HGET', gameKey, 'player1_rating')) or 0
+    local player2Rating = tonumber(redis.call('HGET', gameKey, 'player2_rating')) or 0
+
+    local expected = 1 / (1 + 10 ^ ((player2Rating - player1Rating) / 400))
+    local delta = math.floor(kFactor * (player1Score - expected) + 0.5)
+    return delta, -delta
+end
+`
+
+// ratingKFactor is the maximum number of rating points that can change hands in one game
+const ratingKFactor = 32
+
+// twoPlayerFunction is prepended to any script that may end a two-player game. It must come after
+// gameEventFunction and ratingDeltasFunction.
+// endTwoPlayerGame(gameKey, gameId, winnerId, reason, expireSet, persistQueue, extra) completes the game for winnerId,
+// or as a draw when winnerId is empty, and settles ratings. A loser on time has their clock zeroed.
+// Any fields in the optional extra table are added to the game_ended event
+var twoPlayerFunction = `
+local function endTwoPlayerGame(gameKey, gameId, winnerId, reason, expireSet, persistQueue, extra)
+    local player1Id = redis.call('HGET', gameKey, 'player1_id')
+    local player2Id = redis.call('HGET', gameKey, 'player2_id')
+
+    -- Work out rating changes (zero for unrated games)
+    local player1Score = 0.5
+    if winnerId == player1Id then
+        player1Score = 1
+    elseif winnerId == player2Id then
+        player1Score = 0
+    end
+    local player1Delta, player2Delta = ratingDeltas(gameKey, player1Score)
+
+    local endTime = tonumber(redis.call('TIME')[1])
+    redis.call('HSET', gameKey,
+        'status', 'completed',
+        'winner_id', winnerId,
+        'win_reason', reason,
+        'end_time', endTime,
+        'player1_rating_delta', player1Delta,
+        'player2_rating_delta', player2Delta
+    )
+    if reason == 'timeout' and winnerId == player1Id then
+        redis.call('HSET', gameKey, 'player2_time_left_ms', 0)
+    elseif reason == 'timeout' and winnerId == player2Id then
+        redis.call('HSET', gameKey, 'player1_time_left_ms', 0)
+    end
+
+    redis.call('ZREM', expireSet, gameId)
+    redis.call('ZREM', '` + botTurnSet + `', gameId)
+    -- Queue for persisting to Postgres
+    redis.call('ZADD', persistQueue, endTime, gameId)
+
+    local payload = extra or {}
+    payload.winnerId = winnerId
+    payload.reason = reason
+    payload.ratingChanges = {[player1Id] = player1Delta, [player2Id] = player2Delta}
+    appendGameEvent(gameKey, gameId, 'game_ended', cjson.encode(payload))
+end
+`
+
 // AtomicOperationError represents an error from an atomic operation
 type AtomicOperationError struct {
 	Message string
@@ -412,47 +980,43 @@ func (e *AtomicOperationError) Error() string {
 
 // ========== Game Expiration Operations ==========
 
+// gameExpireSet holds active games scored by the unix ms at which the current player's clock runs out
 const gameExpireSet = "game:expire"
-const turnTimeoutSeconds = 100
-
-// AddGameToExpireQueue adds a game to the expiration sorted set
-// Score is current timestamp + timeout seconds
-func (r *RedisClient) AddGameToExpireQueue(gameID string) error {
-	expireAt := time.Now().Unix() + turnTimeoutSeconds
-	return r.client.ZAdd(ctx, gameExpireSet, redis.Z{
-		Score:  float64(expireAt),
-		Member: gameID,
-	}).Err()
-}
 
-// AtomicUpdateGameExpiration atomically removes and re-adds a game to the expiration queue
-// Used when a player makes a valid move to reset the turn timer
-var updateGameExpirationScript = `
-local expireSet = KEYS[1]
-local gameId = ARGV[1]
-local newExpireTime = ARGV[2]
+// gamePersistQueue holds completed games waiting to be written to Postgres, scored by end time.
+// Every script that moves a game to 'completed' must add the game here in the same call
+const gamePersistQueue = "game:persist:queue"
 
-redis.call('ZREM', expireSet, gameId)
-redis.call('ZADD', expireSet, newExpireTime, gameId)
-return 1
-`
+// clockFunction is prepended to any script that reads or runs the players' clocks.
+// nowMs() returns the Redis server time in unix milliseconds and
+// clocks(gameKey, player1Id, player2Id) returns each player's remaining time keyed by player ID
+var clockFunction = `
+local function nowMs()
+    local timeResult = redis.call('TIME')
+    return tonumber(timeResult[1]) * 1000 + math.floor(tonumber(timeResult[2]) / 1000)
+end
 
-func (r *RedisClient) AtomicUpdateGameExpiration(gameID string) error {
-	expireAt := time.Now().Unix() + turnTimeoutSeconds
-	_, err := r.client.Eval(ctx, updateGameExpirationScript, []string{gameExpireSet}, gameID, expireAt).Result()
-	return err
-}
+local function clocks(gameKey, player1Id, player2Id)
+    return {
+        [player1Id] = tonumber(redis.call('HGET', gameKey, 'player1_time_left_ms')) or 0,
+        [player2Id] = tonumber(redis.call('HGET', gameKey, 'player2_time_left_ms')) or 0
+    }
+end
+`
 
 // RemoveGameFromExpireQueue removes a game from the expiration queue (when game ends normally)
 func (r *RedisClient) RemoveGameFromExpireQueue(gameID string) error {
 	return r.client.ZRem(ctx, gameExpireSet, gameID).Err()
 }
 
-// AtomicClaimAndEndExpiredGames atomically claims expired games and ends them
+// AtomicClaimAndEndExpiredGames atomically claims games where the current player's clock has run out and ends them.
+// Games for more than two players eliminate that player instead and only end when one player is left,
+// and races and practice games that reach their time limit end without a winner
 // Returns a list of ended games with their winners
 // This prevents race conditions where a player moves between claim and end
-var claimAndEndExpiredGamesScript = `
+var claimAndEndExpiredGamesScript = gameEventFunction + ratingDeltasFunction + twoPlayerFunction + clockFunction + playersFunction + practiceFunction + `
 local expireSet = KEYS[1]
+local persistQueue = KEYS[2]
 local gamePrefix = 'game:'
 local now = ARGV[1]
 local limit = ARGV[2]
@@ -469,7 +1033,24 @@ for i, gameId in ipairs(expired) do
     local status = redis.call('HGET', gameKey, 'status')
     
     -- Only end games that are still active (not already ended)
-    if status == 'active' then
+    if status == 'active' and isMultiplayer(gameKey) then
+        -- Knock out the player whose clock ran out, the game only ends when one player is left.
+        -- Removed first as the next player's deadline is added back to the expire set
+        redis.call('ZREM', expireSet, gameId)
+        local currentTurnId = redis.call('HGET', gameKey, 'current_turn_id')
+        local _, winnerId = eliminatePlayer(gameKey, gameId, currentTurnId, 'timeout', expireSet, persistQueue)
+        if winnerId ~= '' then
+            table.insert(results, {gameId, winnerId})
+        end
+    elseif status == 'active' and isPractice(gameKey) then
+        -- Time is up, or the player left a game against the dictionary idle, it ends with the score so far
+        endPracticeGame(gameKey, gameId, 'timeout', expireSet, persistQueue)
+        table.insert(results, {gameId, ''})
+    elseif status == 'active' and redis.call('HGET', gameKey, 'type') == 'race' then
+        -- Nobody reached the target in time, the race ends without a winner
+        endTwoPlayerGame(gameKey, gameId, '', 'timeout', expireSet, persistQueue)
+        table.insert(results, {gameId, ''})
+    elseif status == 'active' then
         local currentTurnId = redis.call('HGET', gameKey, 'current_turn_id')
         local player1Id = redis.call('HGET', gameKey, 'player1_id')
         local player2Id = redis.call('HGET', gameKey, 'player2_id')
@@ -480,22 +1061,7 @@ for i, gameId in ipairs(expired) do
             winnerId = player2Id
         end
         
-        -- Atomically end the game
-        local timeResult = redis.call('TIME')
-        local endTime = tonumber(timeResult[1])
-        redis.call('HSET', gameKey, 
-            'status', 'completed',
-            'winner_id', winnerId,
-            'win_reason', 'timeout',
-            'end_time', endTime
-        )
-        
-        -- Remove from expire set
-        redis.call('ZREM', expireSet, gameId)
-        
-        -- Publish JSON event for clients
-        local jsonMsg = '{"type":"game_ended","gameId":"' .. gameId .. '","payload":{"winnerId":"' .. winnerId .. '","reason":"timeout"}}'
-        redis.call('PUBLISH', gameKey, jsonMsg)
+        endTwoPlayerGame(gameKey, gameId, winnerId, 'timeout', expireSet, persistQueue)
         
         -- Add to results
         table.insert(results, {gameId, winnerId})
@@ -515,8 +1081,8 @@ type ExpiredGameResult struct {
 }
 
 func (r *RedisClient) AtomicClaimAndEndExpiredGames(limit int) ([]ExpiredGameResult, error) {
-	now := time.Now().Unix()
-	result, err := r.client.Eval(ctx, claimAndEndExpiredGamesScript, []string{gameExpireSet}, now, limit).Result()
+	now := time.Now().UnixMilli()
+	result, err := r.client.Eval(ctx, claimAndEndExpiredGamesScript, []string{gameExpireSet, gamePersistQueue}, now, limit).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -557,7 +1123,7 @@ return expired
 `
 
 func (r *RedisClient) AtomicClaimExpiredGames(limit int) ([]string, error) {
-	now := time.Now().Unix()
+	now := time.Now().UnixMilli()
 	result, err := r.client.Eval(ctx, claimExpiredGamesScript, []string{gameExpireSet}, now, limit).Result()
 	if err != nil {
 		return nil, err
@@ -588,54 +1154,125 @@ func (r *RedisClient) EndGameByTimeout(gameID string, winnerID string) error {
 	).Err()
 }
 
-// AtomicJoinGameSession atomically increments connected_count and starts game if both players connected
-// Returns: newConnectedCount, gameStarted, error
-var joinGameSessionScript = `
+// AtomicJoinGameSession atomically increments connected_count and starts game if both players connected.
+// A practice game has a single player and starts as soon as they connect
+// Returns: newConnectedCount, gameStarted, lastSeq (the last event before joining), error
+var joinGameSessionScript = gameEventFunction + clockFunction + practiceFunction + botFunction + `
 local gameKey = KEYS[1]
 local expireSet = KEYS[2]
-local turnTimeout = ARGV[1]
+local defaultTimeBase = ARGV[1]
+local defaultTimeIncrement = ARGV[2]
 
 local status = redis.call('HGET', gameKey, 'status')
 if not status then
-    return {-1, false, 'game_not_found'}
+    return {-1, false, 'game_not_found', 0}
 end
 
 -- Allow joining games in waiting (player1 before match), ready (both matched), or active (reconnect)
 if status ~= 'waiting' and status ~= 'ready' and status ~= 'active' then
-    return {-1, false, 'game_not_joinable'}
+    return {-1, false, 'game_not_joinable', 0}
 end
 
+local lastSeq = tonumber(redis.call('HGET', gameKey, 'event_seq')) or 0
 local newCount = redis.call('HINCRBY', gameKey, 'connected_count', 1)
+local gameType = redis.call('HGET', gameKey, 'type')
+local requiredCount = 2
+if gameType == 'practice' then
+    requiredCount = 1
+end
 
 -- Only start the game when:
--- 1. Both players are connected (count == 2)
+-- 1. Both players are connected (count == 2), or the player of a practice game
 -- 2. Status is 'ready' (both players have been matched via matchmaking)
-if newCount == 2 and status == 'ready' then
-    local timeResult = redis.call('TIME')
-    local now = tonumber(timeResult[1])
-    redis.call('HSET', gameKey, 'status', 'active', 'start_time', now)
+if newCount == requiredCount and status == 'ready' then
+    local now = nowMs()
+    local timeBase = tonumber(redis.call('HGET', gameKey, 'time_base_ms')) or 0
+    local timeIncrement = tonumber(redis.call('HGET', gameKey, 'time_increment_ms')) or 0
+    if timeBase <= 0 then
+        timeBase = tonumber(defaultTimeBase)
+        timeIncrement = tonumber(defaultTimeIncrement)
+    end
+
+    -- Both clocks start full, player 1's starts running
+    redis.call('HSET', gameKey,
+        'status', 'active',
+        'start_time', math.floor(now / 1000),
+        'time_base_ms', timeBase,
+        'time_increment_ms', timeIncrement,
+        'player1_time_left_ms', timeBase,
+        'player2_time_left_ms', timeBase,
+        'turn_started_at_ms', now
+    )
     
-    -- Add to expiration queue
-    local expireAt = now + tonumber(turnTimeout)
+    -- Add to expiration queue for when player 1's clock runs out, practice against the dictionary
+    -- is untimed and only ends once the player has been idle too long
     local gameId = string.gsub(gameKey, 'game:', '')
-    redis.call('ZADD', expireSet, expireAt, gameId)
+    local practiceMode = redis.call('HGET', gameKey, 'practice_mode')
+    if practiceMode ~= 'dictionary' then
+        redis.call('ZADD', expireSet, now + timeBase, gameId)
+    else
+        extendPracticeIdle(gameKey, gameId, expireSet)
+    end
+    -- A bot moving first is queued for the arbiter
+    queueBotTurn(gameKey, gameId, redis.call('HGET', gameKey, 'current_turn_id'), now)
+    
+    -- Record game started event
+    local game = redis.call('HGETALL', gameKey)
+    local fields = {}
+    for i = 1, #game, 2 do
+        fields[game[i]] = game[i + 1]
+    end
+    local payload = {
+        currentWord = fields['current_word'],
+        currentTurnId = fields['current_turn_id'],
+        player1Id = fields['player1_id'],
+        player1Name = fields['player1_name'],
+        player2Id = fields['player2_id'],
+        player2Name = fields['player2_name'],
+        player1Rating = tonumber(fields['player1_rating']) or 0,
+        player2Rating = tonumber(fields['player2_rating']) or 0,
+        rated = fields['rated'] == '1',
+        startWord = fields['current_word'],
+        timeControl = {baseMs = timeBase, incrementMs = timeIncrement},
+        timeLeftMs = clocks(gameKey, fields['player1_id'], fields['player2_id']),
+        turnStartedAt = now
+    }
+    -- In a race both players move at once and the base time is the time limit for the whole race
+    if fields['type'] == 'race' then
+        payload.type = 'race'
+        payload.targetWord = fields['target_word']
+        payload.par = tonumber(fields['par']) or 0
+    end
+    if gameType == 'practice' then
+        payload.type = 'practice'
+        payload.practiceMode = practiceMode
+        payload.score = 0
+        payload.hintCost = tonumber(ARGV[3])
+        payload.timeLeftMs = nil
+        if practiceMode == 'clock' then
+            payload.timeLeftMs = {[fields['player1_id']] = timeBase}
+        end
+    end
+    appendGameEvent(gameKey, gameId, 'game_started', cjson.encode(payload))
     
-    return {newCount, true, ''}
+    return {newCount, true, '', lastSeq}
 end
 
-return {newCount, false, ''}
+return {newCount, false, '', lastSeq}
 `
 
-func (r *RedisClient) AtomicJoinGameSession(gameID string) (int, bool, error) {
+func (r *RedisClient) AtomicJoinGameSession(gameID string) (int, bool, int64, error) {
 	gameKey := gameKeyPrefix + gameID
-	result, err := r.client.Eval(ctx, joinGameSessionScript, []string{gameKey, gameExpireSet}, turnTimeoutSeconds).Result()
+	result, err := r.client.Eval(ctx, joinGameSessionScript, []string{gameKey, gameExpireSet},
+		entities.DefaultTimeBaseMs, entities.DefaultTimeIncrementMs, entities.PracticeHintCost,
+	).Result()
 	if err != nil {
-		return 0, false, err
+		return 0, false, 0, err
 	}
 
 	arr, ok := result.([]interface{})
-	if !ok || len(arr) != 3 {
-		return 0, false, nil
+	if !ok || len(arr) != 4 {
+		return 0, false, 0, nil
 	}
 
 	count, _ := arr[0].(int64)
@@ -644,12 +1281,13 @@ func (r *RedisClient) AtomicJoinGameSession(gameID string) (int, bool, error) {
 		started = s == 1
 	}
 	errMsg, _ := arr[2].(string)
+	lastSeq, _ := arr[3].(int64)
 
 	if errMsg != "" {
-		return 0, false, &AtomicOperationError{Message: errMsg}
+		return 0, false, 0, &AtomicOperationError{Message: errMsg}
 	}
 
-	return int(count), started, nil
+	return int(count), started, lastSeq, nil
 }
 
 // AtomicLeaveGameSession decrements connected_count when a player disconnects
@@ -658,18 +1296,271 @@ func (r *RedisClient) AtomicLeaveGameSession(gameID string) error {
 	return r.client.HIncrBy(ctx, gameKey, "connected_count", -1).Err()
 }
 
+// AtomicDrawAction atomically offers, accepts or declines a draw in an active two-player game.
+// Offering when the opponent has already offered accepts their offer. An accepted draw ends
+// the game with no winner and a draw_agreed reason, and ratings move as for a drawn game
+var drawActionScript = gameEventFunction + ratingDeltasFunction + twoPlayerFunction + `
+local gameKey = KEYS[1]
+local expireSet = KEYS[2]
+local persistQueue = KEYS[3]
+local gameId = ARGV[1]
+local playerId = ARGV[2]
+local action = ARGV[3]
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return {'', 'game_not_found'}
+end
+if status ~= 'active' then
+    return {'', 'game_not_active'}
+end
+if (tonumber(redis.call('HGET', gameKey, 'max_players')) or 0) > 2 or redis.call('HGET', gameKey, 'type') == 'practice' then
+    return {'', 'not_supported'}
+end
+
+local player1Id = redis.call('HGET', gameKey, 'player1_id')
+local player2Id = redis.call('HGET', gameKey, 'player2_id')
+if playerId ~= player1Id and playerId ~= player2Id then
+    return {'', 'not_in_game'}
+end
+
+local opponentId = player1Id
+if playerId == player1Id then
+    opponentId = player2Id
+end
+
+local offeredBy = redis.call('HGET', gameKey, 'draw_offered_by')
+
+if action == 'offer' and offeredBy ~= opponentId then
+    if offeredBy == playerId then
+        return {'', 'draw_already_offered'}
+    end
+    redis.call('HSET', gameKey, 'draw_offered_by', playerId)
+    appendGameEvent(gameKey, gameId, 'draw_offered', cjson.encode({playerId = playerId}))
+    return {'offered', ''}
+end
+
+if offeredBy ~= opponentId then
+    return {'', 'no_draw_offer'}
+end
+
+if action == 'decline' then
+    redis.call('HDEL', gameKey, 'draw_offered_by')
+    appendGameEvent(gameKey, gameId, 'draw_declined', cjson.encode({playerId = playerId}))
+    return {'declined', ''}
+end
+
+-- Both players agreed, end the game as a draw
+redis.call('HDEL', gameKey, 'draw_offered_by')
+endTwoPlayerGame(gameKey, gameId, '', 'draw_agreed', expireSet, persistQueue)
+
+return {'accepted', ''}
+`
+
+// AtomicDrawAction applies a draw action ("offer", "accept" or "decline") for a player.
+// Returns "offered", "accepted" or "declined"
+func (r *RedisClient) AtomicDrawAction(gameID string, playerID string, action string) (string, error) {
+	gameKey := gameKeyPrefix + gameID
+
+	result, err := r.client.Eval(ctx, drawActionScript,
+		[]string{gameKey, gameExpireSet, gamePersistQueue},
+		gameID, playerID, action,
+	).Result()
+	if err != nil {
+		return "", err
+	}
+
+	arr, ok := result.([]interface{})
+	if !ok || len(arr) != 2 {
+		return "", &AtomicOperationError{Message: "unexpected_result"}
+	}
+
+	status, _ := arr[0].(string)
+	errMsg, _ := arr[1].(string)
+
+	if errMsg != "" {
+		return "", &AtomicOperationError{Message: errMsg}
+	}
+
+	return status, nil
+}
+
+// AtomicRequestRematch atomically offers or accepts a rematch of a completed two-player, turn-based game.
+// A player's offer is accepted by the opponent accepting or offering too, and a bot accepts straight away.
+// On acceptance newGameID is claimed as the rematch so only one new game is created.
+// Returns "offered" or "accepted"
+var requestRematchScript = gameEventFunction + `
+local gameKey = KEYS[1]
+local gameId = ARGV[1]
+local playerId = ARGV[2]
+local action = ARGV[3]
+local opponentIsBot = ARGV[4] == '1'
+local newGameId = ARGV[5]
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return {'', 'game_not_found'}
+end
+if status ~= 'completed' then
+    return {'', 'game_not_completed'}
+end
+local gameType = redis.call('HGET', gameKey, 'type')
+if (tonumber(redis.call('HGET', gameKey, 'max_players')) or 0) > 2 or gameType == 'race' or gameType == 'practice' then
+    return {'', 'not_supported'}
+end
+
+local player1Id = redis.call('HGET', gameKey, 'player1_id')
+local player2Id = redis.call('HGET', gameKey, 'player2_id')
+if playerId ~= player1Id and playerId ~= player2Id then
+    return {'', 'not_in_game'}
+end
+
+local rematchGameId = redis.call('HGET', gameKey, 'rematch_game_id')
+if rematchGameId and rematchGameId ~= '' then
+    return {'', 'rematch_already_started'}
+end
+
+local opponentId = player1Id
+if playerId == player1Id then
+    opponentId = player2Id
+end
+
+local offeredBy = redis.call('HGET', gameKey, 'rematch_offered_by')
+if offeredBy == opponentId or opponentIsBot then
+    redis.call('HSET', gameKey, 'rematch_game_id', newGameId)
+    return {'accepted', ''}
+end
+
+if action == 'accept' then
+    return {'', 'no_rematch_offer'}
+end
+if offeredBy == playerId then
+    return {'', 'rematch_already_offered'}
+end
+
+redis.call('HSET', gameKey, 'rematch_offered_by', playerId)
+appendGameEvent(gameKey, gameId, 'rematch_offered', cjson.encode({playerId = playerId}))
+
+return {'offered', ''}
+`
+
+func (r *RedisClient) AtomicRequestRematch(gameID string, playerID string, accept bool, opponentIsBot bool, newGameID string) (string, error) {
+	gameKey := gameKeyPrefix + gameID
+
+	action := "offer"
+	if accept {
+		action = "accept"
+	}
+
+	result, err := r.client.Eval(ctx, requestRematchScript, []string{gameKey},
+		gameID, playerID, action, opponentIsBot, newGameID,
+	).Result()
+	if err != nil {
+		return "", err
+	}
+
+	arr, ok := result.([]interface{})
+	if !ok || len(arr) != 2 {
+		return "", &AtomicOperationError{Message: "unexpected_result"}
+	}
+
+	status, _ := arr[0].(string)
+	errMsg, _ := arr[1].(string)
+
+	if errMsg != "" {
+		return "", &AtomicOperationError{Message: errMsg}
+	}
+
+	return status, nil
+}
+
+// AtomicReleaseRematch atomically clears a rematch claim whose game could not be created,
+// so the players can try again. A claim for any other game is left alone
+var releaseRematchScript = `
+local gameKey = KEYS[1]
+local newGameId = ARGV[1]
+
+if redis.call('HGET', gameKey, 'rematch_game_id') == newGameId then
+    redis.call('HDEL', gameKey, 'rematch_game_id')
+end
+return ''
+`
+
+func (r *RedisClient) AtomicReleaseRematch(gameID string, newGameID string) error {
+	return r.client.Eval(ctx, releaseRematchScript, []string{gameKeyPrefix + gameID}, newGameID).Err()
+}
+
+// AtomicUpdateSpectators atomically adds (delta 1) or removes (delta -1) a spectator of a live game
+// and records a spectator_count event. Spectators never count towards connected_count,
+// so they can't affect the game starting. Returns: spectator count, seq of the last game event
+var updateSpectatorsScript = gameEventFunction + `
+local gameKey = KEYS[1]
+local gameId = ARGV[1]
+local delta = tonumber(ARGV[2])
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return {0, 0, 'game_not_found'}
+end
+
+local live = status == 'ready' or status == 'active'
+if delta > 0 and not live then
+    return {0, 0, 'game_not_active'}
+end
+
+local count = redis.call('HINCRBY', gameKey, 'spectator_count', delta)
+if count < 0 then
+    count = 0
+    redis.call('HSET', gameKey, 'spectator_count', 0)
+end
+
+-- Nobody is listening once the game is over
+local seq = tonumber(redis.call('HGET', gameKey, 'event_seq')) or 0
+if live then
+    seq = appendGameEvent(gameKey, gameId, 'spectator_count', cjson.encode({count = count}))
+end
+
+return {count, seq, ''}
+`
+
+func (r *RedisClient) AtomicUpdateSpectators(gameID string, delta int) (int, int64, error) {
+	gameKey := gameKeyPrefix + gameID
+
+	result, err := r.client.Eval(ctx, updateSpectatorsScript, []string{gameKey}, gameID, delta).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	arr, ok := result.([]interface{})
+	if !ok || len(arr) != 3 {
+		return 0, 0, &AtomicOperationError{Message: "unexpected_result"}
+	}
+
+	count, _ := arr[0].(int64)
+	lastSeq, _ := arr[1].(int64)
+	errMsg, _ := arr[2].(string)
+
+	if errMsg != "" {
+		return 0, 0, &AtomicOperationError{Message: errMsg}
+	}
+
+	return int(count), lastSeq, nil
+}
+
 // AtomicSubmitWord atomically validates and applies a word submission
 // Returns: success, newWord, nextTurnPlayerID, error
-// Also tracks played words in a set to prevent duplicates
-var submitWordScript = `
+// Also tracks played words in a set to prevent duplicates and records the word_submitted event.
+// The mover's clock is charged for the turn and credited the increment, then the opponent's clock starts.
+// Games for more than two players pass the turn to the next player still in the game,
+// and handing the turn to a bot queues it for the arbiter to play
+var submitWordScript = gameEventFunction + clockFunction + playersFunction + botFunction + `
 local gameKey = KEYS[1]
 local expireSet = KEYS[2]
 local wordsKey = KEYS[3]
 local movesKey = KEYS[4]
 local playerId = ARGV[1]
 local newWord = ARGV[2]
-local turnTimeout = ARGV[3]
-local playerName = ARGV[4]
+local playerName = ARGV[3]
 
 local status = redis.call('HGET', gameKey, 'status')
 if status ~= 'active' then
@@ -681,8 +1572,13 @@ if currentTurnId ~= playerId then
     return {false, '', '', 'not_your_turn'}
 end
 
--- Check if word has already been played
-if redis.call('SISMEMBER', wordsKey, newWord) == 1 then
+local wordLength = tonumber(redis.call('HGET', gameKey, 'word_length')) or 0
+if wordLength > 0 and string.len(newWord) ~= wordLength then
+    return {false, '', '', 'invalid_word_length'}
+end
+
+-- Check if word has already been played, unless the game allows repeats
+if redis.call('HGET', gameKey, 'allow_repeats') ~= '1' and redis.call('SISMEMBER', wordsKey, newWord) == 1 then
     return {false, '', '', 'word_already_played'}
 end
 
@@ -690,30 +1586,97 @@ end
 local player1Id = redis.call('HGET', gameKey, 'player1_id')
 local player2Id = redis.call('HGET', gameKey, 'player2_id')
 local nextTurnId = player1Id
+local clockField = 'player2_time_left_ms'
+local nextClockField = 'player1_time_left_ms'
 if playerId == player1Id then
     nextTurnId = player2Id
+    clockField = 'player1_time_left_ms'
+    nextClockField = 'player2_time_left_ms'
+end
+
+-- Games for more than two players keep each clock with the player's seat
+local players = nil
+local mover = nil
+local nextPlayer = nil
+if isMultiplayer(gameKey) then
+    players = loadPlayers(gameKey)
+    local _, seat = findPlayer(players, playerId)
+    mover = seat
+    nextPlayer = nextActivePlayer(players, playerId)
+    nextTurnId = nextPlayer.id
+end
+
+-- Charge the mover for this turn, the arbiter ends the game if they have run out
+local now = nowMs()
+local turnStartedAt = tonumber(redis.call('HGET', gameKey, 'turn_started_at_ms')) or now
+local timeLeft = 0
+if mover then
+    timeLeft = mover.timeLeftMs
+else
+    timeLeft = tonumber(redis.call('HGET', gameKey, clockField)) or 0
+end
+timeLeft = timeLeft - (now - turnStartedAt)
+if timeLeft <= 0 then
+    return {false, '', '', 'out_of_time'}
+end
+timeLeft = timeLeft + (tonumber(redis.call('HGET', gameKey, 'time_increment_ms')) or 0)
+
+-- Moving instead of answering a draw offer declines it
+local drawOfferedBy = redis.call('HGET', gameKey, 'draw_offered_by')
+local declinesDraw = drawOfferedBy and drawOfferedBy ~= playerId
+if declinesDraw then
+    redis.call('HDEL', gameKey, 'draw_offered_by')
 end
 
 -- Add word to played words set
 redis.call('SADD', wordsKey, newWord)
 
 -- Add move to moves list with player info and timestamp
-local timestamp = redis.call('TIME')[1]
+local timestamp = tonumber(redis.call('TIME')[1])
 local move = cjson.encode({playerId = playerId, playerName = playerName, word = newWord, timestamp = timestamp})
 redis.call('RPUSH', movesKey, move)
 
--- Update game state
-redis.call('HSET', gameKey, 
-    'current_word', newWord,
-    'current_turn_id', nextTurnId
-)
+-- Update game state and start the opponent's clock
+local nextTimeLeft = 0
+local timeLeftMs = nil
+if players then
+    mover.timeLeftMs = timeLeft
+    savePlayers(gameKey, players)
+    redis.call('HSET', gameKey,
+        'current_word', newWord,
+        'current_turn_id', nextTurnId,
+        'turn_started_at_ms', now
+    )
+    nextTimeLeft = nextPlayer.timeLeftMs
+    timeLeftMs = playerClocks(players)
+else
+    redis.call('HSET', gameKey, 
+        'current_word', newWord,
+        'current_turn_id', nextTurnId,
+        clockField, timeLeft,
+        'turn_started_at_ms', now
+    )
+    nextTimeLeft = tonumber(redis.call('HGET', gameKey, nextClockField)) or 0
+    timeLeftMs = clocks(gameKey, player1Id, player2Id)
+end
 
--- Reset turn timer
-local timeResult = redis.call('TIME')
-local expireAt = tonumber(timeResult[1]) + tonumber(turnTimeout)
+-- Expire when the opponent's clock runs out
 local gameId = string.gsub(gameKey, 'game:', '')
-redis.call('ZREM', expireSet, gameId)
-redis.call('ZADD', expireSet, expireAt, gameId)
+redis.call('ZADD', expireSet, now + nextTimeLeft, gameId)
+queueBotTurn(gameKey, gameId, nextTurnId, now)
+
+-- Record word submitted event
+appendGameEvent(gameKey, gameId, 'word_submitted', cjson.encode({
+    playerId = playerId,
+    playerName = playerName,
+    word = newWord,
+    currentTurnId = nextTurnId,
+    timeLeftMs = timeLeftMs,
+    turnStartedAt = now
+}))
+if declinesDraw then
+    appendGameEvent(gameKey, gameId, 'draw_declined', cjson.encode({playerId = playerId}))
+end
 
 return {true, newWord, nextTurnId, ''}
 `
@@ -722,7 +1685,7 @@ func (r *RedisClient) AtomicSubmitWord(gameID string, playerID string, playerNam
 	gameKey := gameKeyPrefix + gameID
 	wordsKey := gameKeyPrefix + gameID + ":words"
 	movesKey := gameKeyPrefix + gameID + ":moves"
-	result, err := r.client.Eval(ctx, submitWordScript, []string{gameKey, gameExpireSet, wordsKey, movesKey}, playerID, newWord, turnTimeoutSeconds, playerName).Result()
+	result, err := r.client.Eval(ctx, submitWordScript, []string{gameKey, gameExpireSet, wordsKey, movesKey}, playerID, newWord, playerName).Result()
 	if err != nil {
 		return false, "", "", err
 	}
@@ -787,3 +1750,15 @@ func (r *RedisClient) GetMoves(gameID string) ([]MoveRecord, error) {
 
 	return moves, nil
 }
+
+// ========== Game Persistence Operations ==========
+
+// GetGamesToPersist returns up to limit completed games waiting to be written to Postgres, oldest first
+func (r *RedisClient) GetGamesToPersist(limit int) ([]string, error) {
+	return r.client.ZRange(ctx, gamePersistQueue, 0, int64(limit-1)).Result()
+}
+
+// RemoveGameFromPersistQueue marks a game as persisted
+func (r *RedisClient) RemoveGameFromPersistQueue(gameID string) error {
+	return r.client.ZRem(ctx, gamePersistQueue, gameID).Err()
+}
@@ -0,0 +1,354 @@
+package redisclient
+
+import (
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+)
+
+// ========== Multi-player Game Operations ==========
+
+// Games with max_players above two keep their seats in the players field as a JSON array of
+// {id, name, timeLeftMs, eliminated} in turn order, with the host first. Each player has their own clock
+// and running out of time eliminates them instead of ending the game. The last player left wins.
+// In team games each seat also has a team, and a team is knocked out together. The last team left wins
+
+// playersFunction is prepended to any script that handles games for more than two players.
+// It must come after gameEventFunction and clockFunction.
+// endMultiplayerGame(gameKey, gameId, winnerId, reason, expireSet, persistQueue) completes the game for winnerId and their team.
+// eliminatePlayer(gameKey, gameId, playerId, reason, expireSet, persistQueue) knocks a player (and their team) out,
+// passing the turn on if it was theirs, and ends the game once one player or team is left.
+// Returns false if the player is not in the game or already out, otherwise true and the winner ID, which is empty while the game goes on
+var playersFunction = `
+local function isMultiplayer(gameKey)
+    return (tonumber(redis.call('HGET', gameKey, 'max_players')) or 0) > 2
+end
+
+local function loadPlayers(gameKey)
+    return cjson.decode(redis.call('HGET', gameKey, 'players') or '[]')
+end
+
+local function savePlayers(gameKey, players)
+    redis.call('HSET', gameKey, 'players', cjson.encode(players))
+end
+
+local function findPlayer(players, playerId)
+    for i, player in ipairs(players) do
+        if player.id == playerId then
+            return i, player
+        end
+    end
+    return nil, nil
+end
+
+-- The first player still in the game after playerId in turn order
+local function nextActivePlayer(players, playerId)
+    local index = findPlayer(players, playerId) or 0
+    for offset = 1, #players do
+        local player = players[(index + offset - 1) % #players + 1]
+        if not player.eliminated then
+            return player
+        end
+    end
+    return nil
+end
+
+local function activePlayers(players)
+    local active = {}
+    for _, player in ipairs(players) do
+        if not player.eliminated then
+            table.insert(active, player)
+        end
+    end
+    return active
+end
+
+-- Number of players, or teams in a team game, still in the game
+local function activeSides(players)
+    local sides = {}
+    local count = 0
+    for _, player in ipairs(activePlayers(players)) do
+        local side = player.team or player.id
+        if not sides[side] then
+            sides[side] = true
+            count = count + 1
+        end
+    end
+    return count
+end
+
+local function playerClocks(players)
+    local result = {}
+    for _, player in ipairs(players) do
+        result[player.id] = player.timeLeftMs
+    end
+    return result
+end
+
+-- Multi-player games are never rated
+local function endMultiplayerGame(gameKey, gameId, winnerId, reason, expireSet, persistQueue)
+    local players = loadPlayers(gameKey)
+    local _, winner = findPlayer(players, winnerId)
+    local winningTeam = 0
+    if winner and winner.team then
+        winningTeam = winner.team
+    end
+
+    local endTime = math.floor(nowMs() / 1000)
+    redis.call('HSET', gameKey,
+        'status', 'completed',
+        'winner_id', winnerId,
+        'winning_team', winningTeam,
+        'win_reason', reason,
+        'end_time', endTime,
+        'player1_rating_delta', 0,
+        'player2_rating_delta', 0
+    )
+    redis.call('ZREM', expireSet, gameId)
+    redis.call('ZADD', persistQueue, endTime, gameId)
+
+    appendGameEvent(gameKey, gameId, 'game_ended', cjson.encode({
+        winnerId = winnerId,
+        winningTeam = winningTeam,
+        reason = reason,
+        players = players
+    }))
+end
+
+local function eliminatePlayer(gameKey, gameId, playerId, reason, expireSet, persistQueue)
+    local players = loadPlayers(gameKey)
+    local _, player = findPlayer(players, playerId)
+    if not player or player.eliminated then
+        return false, ''
+    end
+    player.eliminated = true
+    if player.team then
+        for _, teammate in ipairs(players) do
+            if teammate.team == player.team then
+                teammate.eliminated = true
+            end
+        end
+    end
+
+    local now = nowMs()
+    local currentTurnId = redis.call('HGET', gameKey, 'current_turn_id')
+    if reason == 'timeout' and currentTurnId == playerId then
+        player.timeLeftMs = 0
+    end
+
+    if activeSides(players) == 1 then
+        local winnerId = activePlayers(players)[1].id
+        savePlayers(gameKey, players)
+        endMultiplayerGame(gameKey, gameId, winnerId, reason, expireSet, persistQueue)
+        return true, winnerId
+    end
+
+    -- Pass the turn on, starting the next player's clock
+    local turnStartedAt = tonumber(redis.call('HGET', gameKey, 'turn_started_at_ms')) or now
+    if currentTurnId == playerId then
+        local nextPlayer = nextActivePlayer(players, playerId)
+        currentTurnId = nextPlayer.id
+        turnStartedAt = now
+        redis.call('HSET', gameKey, 'current_turn_id', currentTurnId, 'turn_started_at_ms', now)
+        redis.call('ZADD', expireSet, now + nextPlayer.timeLeftMs, gameId)
+    end
+    savePlayers(gameKey, players)
+
+    appendGameEvent(gameKey, gameId, 'player_eliminated', cjson.encode({
+        playerId = playerId,
+        reason = reason,
+        currentTurnId = currentTurnId,
+        timeLeftMs = playerClocks(players),
+        turnStartedAt = turnStartedAt
+    }))
+    return true, ''
+end
+`
+
+// AtomicStartLobbyGame atomically starts a multi-player lobby. Only the host can start it, once at least
+// entities.MinPlayers have joined, or every seat is taken in a team game. Every clock starts full and the host moves first.
+// Players that joined the lobby but aren't connected still take their turns, and are eliminated if their clock runs out
+var startLobbyGameScript = gameEventFunction + clockFunction + playersFunction + `
+local gameKey = KEYS[1]
+local expireSet = KEYS[2]
+local waitingSet = KEYS[3]
+local gameId = ARGV[1]
+local hostId = ARGV[2]
+local startWord = ARGV[3]
+local startWordSeed = ARGV[4]
+local minPlayers = tonumber(ARGV[5])
+local defaultTimeBase = ARGV[6]
+local defaultTimeIncrement = ARGV[7]
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return {'', 'game_not_found'}
+end
+if not isMultiplayer(gameKey) then
+    return {'', 'not_a_lobby'}
+end
+if status ~= 'waiting' then
+    return {'', 'game_already_started'}
+end
+if redis.call('HGET', gameKey, 'player1_id') ~= hostId then
+    return {'', 'not_host'}
+end
+
+local players = loadPlayers(gameKey)
+local gameType = redis.call('HGET', gameKey, 'type')
+if gameType == 'team' then
+    minPlayers = tonumber(redis.call('HGET', gameKey, 'max_players'))
+end
+if #players < minPlayers then
+    return {'', 'not_enough_players'}
+end
+
+-- A start word chosen by the host takes priority over the random one
+local startWordSetting = redis.call('HGET', gameKey, 'start_word')
+if startWordSetting and startWordSetting ~= '' and startWordSetting ~= 'random' then
+    startWord = startWordSetting
+end
+
+local now = nowMs()
+local timeBase = tonumber(redis.call('HGET', gameKey, 'time_base_ms')) or 0
+local timeIncrement = tonumber(redis.call('HGET', gameKey, 'time_increment_ms')) or 0
+if timeBase <= 0 then
+    timeBase = tonumber(defaultTimeBase)
+    timeIncrement = tonumber(defaultTimeIncrement)
+end
+for _, player in ipairs(players) do
+    player.timeLeftMs = timeBase
+    player.eliminated = false
+end
+savePlayers(gameKey, players)
+
+redis.call('HSET', gameKey,
+    'status', 'active',
+    'current_word', startWord,
+    'current_turn_id', hostId,
+    'start_word_seed', startWordSeed,
+    'start_time', math.floor(now / 1000),
+    'time_base_ms', timeBase,
+    'time_increment_ms', timeIncrement,
+    'turn_started_at_ms', now
+)
+
+-- Initialize played words and moves with the starting word
+local wordsKey = gameKey .. ':words'
+redis.call('SADD', wordsKey, startWord)
+redis.call('EXPIRE', wordsKey, 86400)
+local movesKey = gameKey .. ':moves'
+redis.call('RPUSH', movesKey, cjson.encode({playerId = '0', playerName = 'start', word = startWord, timestamp = math.floor(now / 1000)}))
+redis.call('EXPIRE', movesKey, 86400)
+
+-- The lobby can no longer be joined
+local joinCode = redis.call('HGET', gameKey, 'join_code')
+if joinCode and joinCode ~= '' then
+    redis.call('DEL', 'game:code:' .. joinCode)
+end
+redis.call('ZREM', waitingSet, gameId)
+
+-- Expire when the host's clock runs out
+redis.call('ZADD', expireSet, now + timeBase, gameId)
+
+appendGameEvent(gameKey, gameId, 'game_started', cjson.encode({
+    currentWord = startWord,
+    currentTurnId = hostId,
+    player1Id = hostId,
+    player1Name = redis.call('HGET', gameKey, 'player1_name'),
+    type = gameType,
+    players = players,
+    rated = false,
+    startWord = startWord,
+    timeControl = {baseMs = timeBase, incrementMs = timeIncrement},
+    timeLeftMs = playerClocks(players),
+    turnStartedAt = now
+}))
+
+return {startWord, ''}
+`
+
+// AtomicStartLobbyGame starts a multi-player lobby hosted by hostID. Returns the start word
+func (r *RedisClient) AtomicStartLobbyGame(gameID string, hostID string, startWord string, startWordSeed int64) (string, error) {
+	gameKey := gameKeyPrefix + gameID
+
+	result, err := r.client.Eval(ctx, startLobbyGameScript,
+		[]string{gameKey, gameExpireSet, waitingGamesSet},
+		gameID, hostID, startWord, startWordSeed, entities.MinPlayers,
+		entities.DefaultTimeBaseMs, entities.DefaultTimeIncrementMs,
+	).Result()
+	if err != nil {
+		return "", err
+	}
+
+	arr, ok := result.([]interface{})
+	if !ok || len(arr) != 2 {
+		return "", &AtomicOperationError{Message: "unexpected_result"}
+	}
+
+	word, _ := arr[0].(string)
+	errMsg, _ := arr[1].(string)
+
+	if errMsg != "" {
+		return "", &AtomicOperationError{Message: errMsg}
+	}
+
+	return word, nil
+}
+
+// AtomicLeaveLobby atomically gives up a player's seat in a lobby that hasn't started and records a player_left event.
+// The host can't leave, they cancel the lobby instead. Team games deal the teams out again so they stay even
+var leaveLobbyScript = gameEventFunction + clockFunction + playersFunction + `
+local gameKey = KEYS[1]
+local gameId = ARGV[1]
+local playerId = ARGV[2]
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return 'game_not_found'
+end
+if not isMultiplayer(gameKey) then
+    return 'not_a_lobby'
+end
+if status ~= 'waiting' then
+    return 'game_already_started'
+end
+if redis.call('HGET', gameKey, 'player1_id') == playerId then
+    return 'host_cannot_leave'
+end
+
+local players = loadPlayers(gameKey)
+local index = findPlayer(players, playerId)
+if not index then
+    return 'not_in_game'
+end
+table.remove(players, index)
+
+if redis.call('HGET', gameKey, 'type') == 'team' then
+    for i, player in ipairs(players) do
+        player.team = (i - 1) % 2 + 1
+    end
+end
+savePlayers(gameKey, players)
+
+appendGameEvent(gameKey, gameId, 'player_left', cjson.encode({
+    playerId = playerId,
+    players = players
+}))
+
+return ''
+`
+
+// AtomicLeaveLobby removes playerID from a lobby that is still waiting for its host to start it
+func (r *RedisClient) AtomicLeaveLobby(gameID string, playerID string) error {
+	gameKey := gameKeyPrefix + gameID
+
+	result, err := r.client.Eval(ctx, leaveLobbyScript, []string{gameKey}, gameID, playerID).Result()
+	if err != nil {
+		return err
+	}
+
+	if errMsg, _ := result.(string); errMsg != "" {
+		return &AtomicOperationError{Message: errMsg}
+	}
+
+	return nil
+}
@@ -0,0 +1,303 @@
+package redisclient
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+)
+
+// ========== Practice Game Operations ==========
+
+// Practice games are played by player 1 alone, so it is always their turn. They use the same game hash,
+// played words and moves as any other game, and start as soon as the player connects.
+// Against the clock a single clock runs for the whole game, against the dictionary there is no clock at all,
+// only an idle deadline so an abandoned game still ends and is persisted
+
+// How long a practice game against the dictionary waits for the player before ending with the score so far
+const practiceIdleTimeout = 30 * time.Minute
+
+// practiceFunction is prepended to any script that may end a practice game. It must come after gameEventFunction and clockFunction.
+// endPracticeGame(gameKey, gameId, reason, expireSet, persistQueue) completes the game with its final score.
+// Practice games have no winner and are never rated.
+// extendPracticeIdle(gameKey, gameId, expireSet) moves the idle deadline of a game against the dictionary on
+var practiceFunction = `
+local function isPractice(gameKey)
+    return redis.call('HGET', gameKey, 'type') == 'practice'
+end
+
+local function extendPracticeIdle(gameKey, gameId, expireSet)
+    if redis.call('HGET', gameKey, 'practice_mode') == 'dictionary' then
+        redis.call('ZADD', expireSet, nowMs() + ` + strconv.FormatInt(practiceIdleTimeout.Milliseconds(), 10) + `, gameId)
+    end
+end
+
+local function endPracticeGame(gameKey, gameId, reason, expireSet, persistQueue)
+    local endTime = tonumber(redis.call('TIME')[1])
+    redis.call('HSET', gameKey,
+        'status', 'completed',
+        'winner_id', '',
+        'win_reason', reason,
+        'end_time', endTime,
+        'player1_rating_delta', 0,
+        'player2_rating_delta', 0
+    )
+    if reason == 'timeout' then
+        redis.call('HSET', gameKey, 'player1_time_left_ms', 0)
+    end
+    redis.call('ZREM', expireSet, gameId)
+    redis.call('ZADD', persistQueue, endTime, gameId)
+
+    appendGameEvent(gameKey, gameId, 'game_ended', cjson.encode({
+        winnerId = '',
+        reason = reason,
+        score = tonumber(redis.call('HGET', gameKey, 'score')) or 0,
+        hintsUsed = tonumber(redis.call('HGET', gameKey, 'hints_used')) or 0,
+        moveCount = redis.call('LLEN', gameKey .. ':moves') - 1
+    }))
+end
+`
+
+// AtomicSubmitPracticeWord atomically plays a word in a practice game and adds its points to the score.
+// Against the clock the player is charged for the time since their last move and credited the increment.
+// A word with no unplayed neighbour doesn't end the game, it records a no_moves_left event
+// so the player can undo the move or end the game themselves
+var submitPracticeWordScript = gameEventFunction + clockFunction + practiceFunction + `
+local gameKey = KEYS[1]
+local expireSet = KEYS[2]
+local wordsKey = KEYS[3]
+local movesKey = KEYS[4]
+local gameId = ARGV[1]
+local playerId = ARGV[2]
+local playerName = ARGV[3]
+local newWord = ARGV[4]
+local movePoints = tonumber(ARGV[5])
+local noMovesLeft = ARGV[6] == '1'
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return {0, 'game_not_found'}
+end
+if status ~= 'active' then
+    return {0, 'game_not_active'}
+end
+if not isPractice(gameKey) then
+    return {0, 'not_a_practice_game'}
+end
+if redis.call('HGET', gameKey, 'player1_id') ~= playerId then
+    return {0, 'not_in_game'}
+end
+if redis.call('SISMEMBER', wordsKey, newWord) == 1 then
+    return {0, 'word_already_played'}
+end
+
+-- Charge the clock, the arbiter ends the game if it has run out
+local now = nowMs()
+local timeLeftMs = nil
+if redis.call('HGET', gameKey, 'practice_mode') == 'clock' then
+    local turnStartedAt = tonumber(redis.call('HGET', gameKey, 'turn_started_at_ms')) or now
+    local timeLeft = (tonumber(redis.call('HGET', gameKey, 'player1_time_left_ms')) or 0) - (now - turnStartedAt)
+    if timeLeft <= 0 then
+        return {0, 'out_of_time'}
+    end
+    timeLeft = timeLeft + (tonumber(redis.call('HGET', gameKey, 'time_increment_ms')) or 0)
+
+    redis.call('HSET', gameKey, 'player1_time_left_ms', timeLeft, 'turn_started_at_ms', now)
+    redis.call('ZADD', expireSet, now + timeLeft, gameId)
+    timeLeftMs = {[playerId] = timeLeft}
+end
+extendPracticeIdle(gameKey, gameId, expireSet)
+
+redis.call('SADD', wordsKey, newWord)
+local timestamp = tonumber(redis.call('TIME')[1])
+redis.call('RPUSH', movesKey, cjson.encode({playerId = playerId, playerName = playerName, word = newWord, timestamp = timestamp}))
+redis.call('HSET', gameKey, 'current_word', newWord)
+local score = redis.call('HINCRBY', gameKey, 'score', movePoints)
+
+appendGameEvent(gameKey, gameId, 'word_submitted', cjson.encode({
+    playerId = playerId,
+    playerName = playerName,
+    word = newWord,
+    currentTurnId = playerId,
+    score = score,
+    timeLeftMs = timeLeftMs,
+    turnStartedAt = now
+}))
+if noMovesLeft then
+    appendGameEvent(gameKey, gameId, 'no_moves_left', cjson.encode({
+        playerId = playerId,
+        currentWord = newWord
+    }))
+end
+
+return {score, ''}
+`
+
+// AtomicSubmitPracticeWord plays newWord for playerID in a practice game, noMovesLeft when newWord
+// has no unplayed neighbour. Returns the new score
+func (r *RedisClient) AtomicSubmitPracticeWord(gameID string, playerID string, playerName string, newWord string, noMovesLeft bool) (int, error) {
+	gameKey := gameKeyPrefix + gameID
+	wordsKey := gameKey + ":words"
+	movesKey := gameKey + ":moves"
+
+	result, err := r.client.Eval(ctx, submitPracticeWordScript,
+		[]string{gameKey, gameExpireSet, wordsKey, movesKey},
+		gameID, playerID, playerName, newWord, entities.PracticeMovePoints, noMovesLeft,
+	).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return parsePracticeScore(result)
+}
+
+// AtomicPracticeHint atomically charges the player of a practice game for a hint and records a hint_given event.
+// The hint is picked for currentWord, so it is refused if a move or undo has changed the word since
+var practiceHintScript = gameEventFunction + clockFunction + practiceFunction + `
+local gameKey = KEYS[1]
+local expireSet = KEYS[2]
+local gameId = ARGV[1]
+local playerId = ARGV[2]
+local currentWord = ARGV[3]
+local hint = ARGV[4]
+local hintCost = tonumber(ARGV[5])
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return {0, 'game_not_found'}
+end
+if status ~= 'active' then
+    return {0, 'game_not_active'}
+end
+if not isPractice(gameKey) then
+    return {0, 'not_a_practice_game'}
+end
+if redis.call('HGET', gameKey, 'player1_id') ~= playerId then
+    return {0, 'not_in_game'}
+end
+if redis.call('HGET', gameKey, 'current_word') ~= currentWord then
+    return {0, 'game_state_changed'}
+end
+
+local score = redis.call('HINCRBY', gameKey, 'score', -hintCost)
+local hintsUsed = redis.call('HINCRBY', gameKey, 'hints_used', 1)
+extendPracticeIdle(gameKey, gameId, expireSet)
+
+appendGameEvent(gameKey, gameId, 'hint_given', cjson.encode({
+    playerId = playerId,
+    word = hint,
+    score = score,
+    hintsUsed = hintsUsed
+}))
+
+return {score, ''}
+`
+
+// AtomicPracticeHint gives playerID the hint for currentWord in a practice game. Returns the new score
+func (r *RedisClient) AtomicPracticeHint(gameID string, playerID string, currentWord string, hint string) (int, error) {
+	gameKey := gameKeyPrefix + gameID
+
+	result, err := r.client.Eval(ctx, practiceHintScript,
+		[]string{gameKey, gameExpireSet},
+		gameID, playerID, currentWord, hint, entities.PracticeHintCost,
+	).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return parsePracticeScore(result)
+}
+
+// AtomicUndoPracticeMove atomically takes back the last word played in a practice game, along with its points.
+// The word can be played again and the previous word becomes the current word. The start word can't be undone,
+// and time spent on the undone move is not given back
+var undoPracticeMoveScript = gameEventFunction + clockFunction + practiceFunction + `
+local gameKey = KEYS[1]
+local wordsKey = KEYS[2]
+local movesKey = KEYS[3]
+local expireSet = KEYS[4]
+local gameId = ARGV[1]
+local playerId = ARGV[2]
+local movePoints = tonumber(ARGV[3])
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return {'', 0, 'game_not_found'}
+end
+if status ~= 'active' then
+    return {'', 0, 'game_not_active'}
+end
+if not isPractice(gameKey) then
+    return {'', 0, 'not_a_practice_game'}
+end
+if redis.call('HGET', gameKey, 'player1_id') ~= playerId then
+    return {'', 0, 'not_in_game'}
+end
+if redis.call('LLEN', movesKey) <= 1 then
+    return {'', 0, 'nothing_to_undo'}
+end
+
+local undone = cjson.decode(redis.call('RPOP', movesKey))
+redis.call('SREM', wordsKey, undone.word)
+local previous = cjson.decode(redis.call('LINDEX', movesKey, -1))
+redis.call('HSET', gameKey, 'current_word', previous.word)
+local score = redis.call('HINCRBY', gameKey, 'score', -movePoints)
+extendPracticeIdle(gameKey, gameId, expireSet)
+
+appendGameEvent(gameKey, gameId, 'move_undone', cjson.encode({
+    playerId = playerId,
+    word = undone.word,
+    currentWord = previous.word,
+    score = score
+}))
+
+return {previous.word, score, ''}
+`
+
+// AtomicUndoPracticeMove takes back playerID's last word in a practice game.
+// Returns: the current word after the undo, the new score, error
+func (r *RedisClient) AtomicUndoPracticeMove(gameID string, playerID string) (string, int, error) {
+	gameKey := gameKeyPrefix + gameID
+	wordsKey := gameKey + ":words"
+	movesKey := gameKey + ":moves"
+
+	result, err := r.client.Eval(ctx, undoPracticeMoveScript,
+		[]string{gameKey, wordsKey, movesKey, gameExpireSet},
+		gameID, playerID, entities.PracticeMovePoints,
+	).Result()
+	if err != nil {
+		return "", 0, err
+	}
+
+	arr, ok := result.([]interface{})
+	if !ok || len(arr) != 3 {
+		return "", 0, &AtomicOperationError{Message: "unexpected_result"}
+	}
+
+	currentWord, _ := arr[0].(string)
+	score, _ := arr[1].(int64)
+	errMsg, _ := arr[2].(string)
+
+	if errMsg != "" {
+		return "", 0, &AtomicOperationError{Message: errMsg}
+	}
+
+	return currentWord, int(score), nil
+}
+
+// parsePracticeScore reads the {score, error} result of a practice script
+func parsePracticeScore(result interface{}) (int, error) {
+	arr, ok := result.([]interface{})
+	if !ok || len(arr) != 2 {
+		return 0, &AtomicOperationError{Message: "unexpected_result"}
+	}
+
+	score, _ := arr[0].(int64)
+	errMsg, _ := arr[1].(string)
+
+	if errMsg != "" {
+		return 0, &AtomicOperationError{Message: errMsg}
+	}
+
+	return int(score), nil
+}
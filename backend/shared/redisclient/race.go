@@ -0,0 +1,107 @@
+package redisclient
+
+// ========== Race Game Operations ==========
+
+// AtomicSubmitRaceWord atomically moves a player on from their own current word in a race game.
+// There are no turns, so fromWord is checked against the player's current word to reject a move made
+// from a stale position. Every move records a race_progress event with the remaining distance to the target,
+// and reaching the target wins the race
+var submitRaceWordScript = gameEventFunction + ratingDeltasFunction + twoPlayerFunction + `
+local gameKey = KEYS[1]
+local expireSet = KEYS[2]
+local persistQueue = KEYS[3]
+local movesKey = KEYS[4]
+local gameId = ARGV[1]
+local playerId = ARGV[2]
+local playerName = ARGV[3]
+local fromWord = ARGV[4]
+local newWord = ARGV[5]
+local distance = tonumber(ARGV[6])
+
+local status = redis.call('HGET', gameKey, 'status')
+if not status then
+    return {0, false, 'game_not_found'}
+end
+if status ~= 'active' then
+    return {0, false, 'game_not_active'}
+end
+if redis.call('HGET', gameKey, 'type') ~= 'race' then
+    return {0, false, 'not_a_race'}
+end
+
+local player1Id = redis.call('HGET', gameKey, 'player1_id')
+local player2Id = redis.call('HGET', gameKey, 'player2_id')
+local wordField = 'player1_word'
+local countField = 'player1_move_count'
+if playerId == player2Id then
+    wordField = 'player2_word'
+    countField = 'player2_move_count'
+elseif playerId ~= player1Id then
+    return {0, false, 'not_in_game'}
+end
+
+if redis.call('HGET', gameKey, wordField) ~= fromWord then
+    return {0, false, 'game_state_changed'}
+end
+
+redis.call('HSET', gameKey, wordField, newWord)
+local moveCount = redis.call('HINCRBY', gameKey, countField, 1)
+
+local timestamp = tonumber(redis.call('TIME')[1])
+redis.call('RPUSH', movesKey, cjson.encode({playerId = playerId, playerName = playerName, word = newWord, timestamp = timestamp}))
+
+appendGameEvent(gameKey, gameId, 'race_progress', cjson.encode({
+    playerId = playerId,
+    word = newWord,
+    moveCount = moveCount,
+    distance = distance
+}))
+
+if newWord ~= redis.call('HGET', gameKey, 'target_word') then
+    return {moveCount, false, ''}
+end
+
+-- First to the target wins
+endTwoPlayerGame(gameKey, gameId, playerId, 'target_reached', expireSet, persistQueue, {
+    par = tonumber(redis.call('HGET', gameKey, 'par')) or 0,
+    moveCounts = {
+        [player1Id] = tonumber(redis.call('HGET', gameKey, 'player1_move_count')) or 0,
+        [player2Id] = tonumber(redis.call('HGET', gameKey, 'player2_move_count')) or 0
+    }
+})
+
+return {moveCount, true, ''}
+`
+
+// AtomicSubmitRaceWord moves playerID from fromWord to newWord, which is distance moves from the target.
+// Returns: the player's move count, whether they reached the target and won, error
+func (r *RedisClient) AtomicSubmitRaceWord(gameID string, playerID string, playerName string, fromWord string, newWord string, distance int) (int, bool, error) {
+	gameKey := gameKeyPrefix + gameID
+	movesKey := gameKey + ":moves"
+
+	result, err := r.client.Eval(ctx, submitRaceWordScript,
+		[]string{gameKey, gameExpireSet, gamePersistQueue, movesKey},
+		gameID, playerID, playerName, fromWord, newWord, distance,
+	).Result()
+	if err != nil {
+		return 0, false, err
+	}
+
+	arr, ok := result.([]interface{})
+	if !ok || len(arr) != 3 {
+		return 0, false, &AtomicOperationError{Message: "unexpected_result"}
+	}
+
+	moveCount, _ := arr[0].(int64)
+	finished := false
+	if f, ok := arr[1].(int64); ok {
+		finished = f == 1
+	}
+	errMsg, _ := arr[2].(string)
+
+	if errMsg != "" {
+		return 0, false, &AtomicOperationError{Message: errMsg}
+	}
+
+	return int(moveCount), finished, nil
+}
@@ -0,0 +1,7 @@
+module github.com/simonPacker7/Delta/backend/shared/word
+
+go 1.25.4
+
+require github.com/simonPacker7/Delta/backend/shared/entities v0.0.0
+
+replace github.com/simonPacker7/Delta/backend/shared/entities => ../entities
@@ -0,0 +1,123 @@
+package word
+
+import (
+	"math/rand"
+	"slices"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+)
+
+// Shortest distance from the start word to the target word in a race, by difficulty
+var targetDistances = map[entities.Difficulty][2]int{
+	entities.DifficultyEasy:   {3, 4},
+	entities.DifficultyMedium: {5, 6},
+	entities.DifficultyHard:   {7, 9},
+}
+
+// Most target words whose distances are kept in memory at once. Only races in progress need
+// their target's distances, so this covers the races an instance is likely to be serving together
+const maxCachedTargets = 32
+
+// cachedDistances is a target word and the distances to it, kept in least recently used order
+type cachedDistances struct {
+	target    string
+	distances map[string]int
+}
+
+// distances returns the number of moves from start to every word reachable from it
+func (s *Service) distances(start string) map[string]int {
+	wordMap := s.wordMaps[len(start)]
+	if _, ok := wordMap[start]; !ok {
+		return nil
+	}
+
+	result := map[string]int{start: 0}
+	queue := []string{start}
+	for i := 0; i < len(queue); i++ {
+		for _, next := range wordMap[queue[i]] {
+			if _, seen := result[next]; !seen {
+				result[next] = result[queue[i]] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return result
+}
+
+// Distance returns the fewest moves needed to get from one word to another, or -1 if it can't be done.
+// Every move in a race is measured against the same target, so the distances to each target are cached
+func (s *Service) Distance(from, to string) int {
+	if len(from) != len(to) {
+		return -1
+	}
+	distance, ok := s.distancesTo(to)[from]
+	if !ok {
+		return -1
+	}
+	return distance
+}
+
+// distancesTo returns the cached distances to target, working them out on first use and dropping
+// the least recently used target once the cache is full. The word graph is undirected, so the
+// distances from target are the distances to it. The search runs without the lock, so races
+// with cached targets aren't held up by one that isn't
+func (s *Service) distancesTo(target string) map[string]int {
+	s.distanceMu.Lock()
+	if element, ok := s.distanceCache[target]; ok {
+		s.distanceOrder.MoveToFront(element)
+		s.distanceMu.Unlock()
+		return element.Value.(*cachedDistances).distances
+	}
+	s.distanceMu.Unlock()
+
+	result := s.distances(target)
+
+	s.distanceMu.Lock()
+	defer s.distanceMu.Unlock()
+	// Another move to the same target may have worked it out first
+	if element, ok := s.distanceCache[target]; ok {
+		s.distanceOrder.MoveToFront(element)
+		return element.Value.(*cachedDistances).distances
+	}
+	s.distanceCache[target] = s.distanceOrder.PushFront(&cachedDistances{target: target, distances: result})
+	if s.distanceOrder.Len() > maxCachedTargets {
+		oldest := s.distanceOrder.Back()
+		s.distanceOrder.Remove(oldest)
+		delete(s.distanceCache, oldest.Value.(*cachedDistances).target)
+	}
+	return result
+}
+
+// PickTarget returns a target word for start whose distance suits the difficulty, and that distance.
+// When no word is far enough away the furthest reachable word is used instead.
+// The same dictionary version, start word, difficulty and seed always give the same target
+func (s *Service) PickTarget(start string, difficulty entities.Difficulty, seed int64) (string, int) {
+	bounds, ok := targetDistances[difficulty]
+	if !ok {
+		bounds = targetDistances[entities.DifficultyMedium]
+	}
+
+	distances := s.distances(start)
+	furthest := 0
+	for _, distance := range distances {
+		furthest = max(furthest, distance)
+	}
+	if furthest == 0 {
+		return start, 0
+	}
+	if furthest < bounds[0] {
+		bounds = [2]int{furthest, furthest}
+	}
+
+	candidates := make([]string, 0)
+	for word, distance := range distances {
+		if distance >= bounds[0] && distance <= bounds[1] {
+			candidates = append(candidates, word)
+		}
+	}
+	slices.Sort(candidates)
+
+	target := candidates[rand.New(rand.NewSource(seed)).Intn(len(candidates))]
+	return target, distances[target]
+}
@@ -0,0 +1,16 @@
+package word
+
+// NewTestService returns a Service with a tiny 4 letter dictionary for tests in other packages.
+// COLD links to CORD and BOLD, and CORD to WORD, so BOLD and WORD are dead ends once COLD and CORD are played.
+// COLD and CORD are the start words
+func NewTestService() *Service {
+	wordMap := map[string][]string{
+		"COLD": {"CORD", "BOLD"},
+		"CORD": {"COLD", "WORD"},
+		"BOLD": {"COLD"},
+		"WORD": {"CORD"},
+	}
+	startWords := []string{"COLD", "CORD"}
+
+	return newService(map[int]map[string][]string{4: wordMap}, map[int][]string{4: startWords}, "test")
+}
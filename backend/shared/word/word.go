@@ -0,0 +1,263 @@
+package word
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+)
+
+// Word lengths a dictionary may be provided for
+var supportedWordLengths = []int{3, 4, 5, 6}
+
+// Start words whose connected component is smaller than this make for games that end in a few moves
+const minComponentSize = 50
+
+// Errors from PickStartWord, games can't be created with these settings
+var (
+	ErrUnsupportedWordLength = errors.New("unsupported word length")
+	ErrInvalidDifficulty     = errors.New("invalid difficulty")
+	ErrNoStartWords          = errors.New("no start words for this difficulty")
+)
+
+// Dictionary files are named by word length inside the assets directory, e.g. 4-WordMap.json
+const (
+	wordMapFileFormat    = "%d-WordMap.json"
+	startWordsFileFormat = "%d-StartWords.json"
+)
+
+// Service holds the word graph and start words for every word length, shared by the
+// worker (start word selection) and game-service (move validation)
+type Service struct {
+	wordMaps   map[int]map[string][]string
+	startWords map[int][]string
+	tiers      map[int]map[entities.Difficulty][]string
+	version    string
+
+	distanceMu    sync.Mutex
+	distanceCache map[string]*list.Element
+	distanceOrder *list.List
+}
+
+// NewService loads the dictionary for every supported word length found in assetsDir.
+// A word length is only available when both its word map and start words are present
+func NewService(assetsDir string) *Service {
+	wordMaps := make(map[int]map[string][]string)
+	startWords := make(map[int][]string)
+	hash := sha256.New()
+
+	for _, length := range supportedWordLengths {
+		wordMapPath := filepath.Join(assetsDir, fmt.Sprintf(wordMapFileFormat, length))
+		startWordsPath := filepath.Join(assetsDir, fmt.Sprintf(startWordsFileFormat, length))
+		if !fileExists(wordMapPath) || !fileExists(startWordsPath) {
+			continue
+		}
+
+		wordMapData, err := os.ReadFile(wordMapPath)
+		if err != nil {
+			log.Fatalf("Failed to read %d letter word map: %v", length, err)
+		}
+		startWordsData, err := os.ReadFile(startWordsPath)
+		if err != nil {
+			log.Fatalf("Failed to read %d letter start words: %v", length, err)
+		}
+
+		var wordMap map[string][]string
+		if err := json.Unmarshal(wordMapData, &wordMap); err != nil {
+			log.Fatalf("Failed to load %d letter word map: %v", length, err)
+		}
+		var words []string
+		if err := json.Unmarshal(startWordsData, &words); err != nil {
+			log.Fatalf("Failed to load %d letter start words: %v", length, err)
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		// The version covers the exact files loaded, so services only agree when their dictionaries match
+		fmt.Fprintf(hash, "%d\n", length)
+		hash.Write(wordMapData)
+		hash.Write(startWordsData)
+
+		log.Printf("Loaded %d %d letter words and %d start words", len(wordMap), length, len(words))
+		wordMaps[length] = wordMap
+		startWords[length] = words
+	}
+
+	if len(wordMaps) == 0 {
+		log.Fatalf("No dictionaries found in %s", assetsDir)
+	}
+
+	version := hex.EncodeToString(hash.Sum(nil))[:12]
+	log.Printf("Dictionary version %s", version)
+
+	return newService(wordMaps, startWords, version)
+}
+
+// newService builds a Service from loaded word maps and start words, splitting each length's start words into tiers
+func newService(wordMaps map[int]map[string][]string, startWords map[int][]string, version string) *Service {
+	tiers := make(map[int]map[entities.Difficulty][]string)
+	for length, words := range startWords {
+		tiers[length] = buildTiers(wordMaps[length], words)
+	}
+
+	return &Service{
+		wordMaps:      wordMaps,
+		startWords:    startWords,
+		tiers:         tiers,
+		version:       version,
+		distanceCache: make(map[string]*list.Element),
+		distanceOrder: list.New(),
+	}
+}
+
+// buildTiers splits the start words into difficulty tiers by branching factor, after
+// dropping any whose connected component is too small for a real game.
+// Easy words have the most moves, hard words the fewest
+func buildTiers(wordMap map[string][]string, startWords []string) map[entities.Difficulty][]string {
+	componentSizes := componentSizes(wordMap)
+
+	eligible := make([]string, 0, len(startWords))
+	for _, word := range startWords {
+		if componentSizes[word] >= minComponentSize {
+			eligible = append(eligible, word)
+		}
+	}
+	// Small dictionaries may not have any large components, fall back to every start word.
+	// Games from this dictionary may end in a few moves, so say so rather than hide it
+	if len(eligible) == 0 {
+		log.Printf("Warning: no %d letter start word is connected to %d words, games may be trivial. Using every start word",
+			len(startWords[0]), minComponentSize)
+		eligible = slices.Clone(startWords)
+	}
+
+	slices.SortFunc(eligible, func(a, b string) int {
+		if diff := len(wordMap[b]) - len(wordMap[a]); diff != 0 {
+			return diff
+		}
+		return strings.Compare(a, b)
+	})
+
+	third := len(eligible) / 3
+	tiers := map[entities.Difficulty][]string{
+		entities.DifficultyEasy:   eligible[:third],
+		entities.DifficultyMedium: eligible[third : len(eligible)-third],
+		entities.DifficultyHard:   eligible[len(eligible)-third:],
+	}
+	for difficulty, words := range tiers {
+		if len(words) == 0 {
+			tiers[difficulty] = eligible
+		}
+	}
+
+	return tiers
+}
+
+// componentSizes returns the size of the connected component each word belongs to
+func componentSizes(wordMap map[string][]string) map[string]int {
+	sizes := make(map[string]int, len(wordMap))
+
+	for start := range wordMap {
+		if sizes[start] != 0 {
+			continue
+		}
+
+		component := []string{start}
+		sizes[start] = -1
+		for i := 0; i < len(component); i++ {
+			for _, next := range wordMap[component[i]] {
+				if sizes[next] == 0 {
+					sizes[next] = -1
+					component = append(component, next)
+				}
+			}
+		}
+
+		for _, word := range component {
+			sizes[word] = len(component)
+		}
+	}
+
+	return sizes
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Version identifies the loaded dictionary, it is stamped on each game at creation
+func (s *Service) Version() string {
+	return s.version
+}
+
+// Moves returns the words one letter change away from word
+func (s *Service) Moves(word string) []string {
+	return s.wordMaps[len(word)][word]
+}
+
+// IsValidMove checks if newWord is a valid move from currentWord using the word map for wordLength
+func (s *Service) IsValidMove(wordLength int, currentWord, newWord string) bool {
+	validMoves, exists := s.wordMaps[wordLength][currentWord]
+	if !exists {
+		return false
+	}
+	return slices.Contains(validMoves, newWord)
+}
+
+// IsValidWord checks if a word exists in the word map for its length
+func (s *Service) IsValidWord(word string) bool {
+	_, exists := s.wordMaps[len(word)][word]
+	return exists
+}
+
+// IsStartWord checks if a word is one of the loaded start words
+func (s *Service) IsStartWord(word string) bool {
+	return slices.Contains(s.startWords[len(word)], word)
+}
+
+// SupportsWordLength checks if there is a dictionary for the given length
+func (s *Service) SupportsWordLength(length int) bool {
+	return len(s.startWords[length]) > 0
+}
+
+// IsDifficulty checks if difficulty is one of the start word tiers
+func IsDifficulty(difficulty entities.Difficulty) bool {
+	switch difficulty {
+	case entities.DifficultyEasy, entities.DifficultyMedium, entities.DifficultyHard:
+		return true
+	}
+	return false
+}
+
+// NewSeed returns a seed for PickStartWord
+func NewSeed() int64 {
+	return rand.Int63()
+}
+
+// PickStartWord returns a start word of the given length from the difficulty tier.
+// The same dictionary version, length, difficulty and seed always give the same word
+func (s *Service) PickStartWord(length int, difficulty entities.Difficulty, seed int64) (string, error) {
+	if !s.SupportsWordLength(length) {
+		return "", ErrUnsupportedWordLength
+	}
+	if !IsDifficulty(difficulty) {
+		return "", ErrInvalidDifficulty
+	}
+	words := s.tiers[length][difficulty]
+	if len(words) == 0 {
+		return "", ErrNoStartWords
+	}
+	return words[rand.New(rand.NewSource(seed)).Intn(len(words))], nil
+}
@@ -5,7 +5,7 @@ go 1.25.4
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
 	github.com/gofiber/fiber/v2 v2.52.10
-	github.com/google/uuid v1.6.0 // indirect
+	github.com/google/uuid v1.6.0
 	github.com/klauspost/compress v1.18.1 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
@@ -32,6 +32,7 @@ require (
 	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/redis/go-redis/v9 v9.17.2 // indirect
 	github.com/simonPacker7/Delta/backend/shared/entities v0.0.0
+	github.com/simonPacker7/Delta/backend/shared/word v0.0.0
 	golang.org/x/crypto v0.45.0
 	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
@@ -42,3 +43,5 @@ replace github.com/simonPacker7/Delta/backend/shared/entities => ../shared/entit
 replace github.com/simonPacker7/Delta/backend/shared/redisclient => ../shared/redisclient
 
 replace github.com/simonPacker7/Delta/backend/shared/postgresclient => ../shared/postgresclient
+
+replace github.com/simonPacker7/Delta/backend/shared/word => ../shared/word
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/word"
 	gameService "github.com/simonPacker7/Delta/backend/worker/services/game"
 )
 
@@ -14,7 +17,13 @@ func FindGame(game *gameService.Service) fiber.Handler {
 			return c.SendStatus(fiber.StatusUnauthorized)
 		}
 
-		response, err := game.FindGame(sessionCtx.ID, sessionCtx.Name)
+		difficulty := entities.Difficulty(c.Query("difficulty"))
+		if difficulty != "" && !word.IsDifficulty(difficulty) {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(ErrorResponse(fiber.NewError(fiber.StatusBadRequest, "invalid difficulty")))
+		}
+
+		response, err := game.FindGame(sessionCtx.ID, sessionCtx.Name, difficulty)
 		if err != nil {
 			c.Status(fiber.StatusInternalServerError)
 			return c.JSON(ErrorResponse(err))
@@ -34,7 +43,7 @@ func GetGame(game *gameService.Service) fiber.Handler {
 
 		gameData, err := game.GetGame(gameID)
 		if err != nil {
-			c.Status(fiber.StatusNotFound)
+			c.Status(gameErrorStatus(err))
 			return c.JSON(ErrorResponse(err))
 		}
 
@@ -42,6 +51,24 @@ func GetGame(game *gameService.Service) fiber.Handler {
 	}
 }
 
+func GetGameMoves(game *gameService.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		gameID := c.Params("id")
+		if gameID == "" {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(ErrorResponse(fiber.NewError(fiber.StatusBadRequest, "game ID is required")))
+		}
+
+		moves, err := game.GetGameMoves(gameID)
+		if err != nil {
+			c.Status(gameErrorStatus(err))
+			return c.JSON(ErrorResponse(err))
+		}
+
+		return c.JSON(moves)
+	}
+}
+
 func CreatePrivateGame(game *gameService.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		sessionCtx, ok := c.Locals("sessionContext").(entities.SessionContext)
@@ -49,7 +76,92 @@ func CreatePrivateGame(game *gameService.Service) fiber.Handler {
 			return c.SendStatus(fiber.StatusUnauthorized)
 		}
 
-		response, err := game.CreatePrivateGame(sessionCtx.ID, sessionCtx.Name)
+		// Settings are optional, an empty body creates a game with the default settings
+		var requestBody entities.CreatePrivateGameInput
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&requestBody); err != nil {
+				c.Status(fiber.StatusBadRequest)
+				return c.JSON(ErrorResponse(err))
+			}
+		}
+
+		if err := game.ValidateSettings(&requestBody.Settings); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(ErrorResponse(err))
+		}
+
+		response, err := game.CreatePrivateGame(sessionCtx.ID, sessionCtx.Name, requestBody.Settings)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(ErrorResponse(err))
+		}
+
+		return c.JSON(response)
+	}
+}
+
+func CreateBotGame(game *gameService.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sessionCtx, ok := c.Locals("sessionContext").(entities.SessionContext)
+		if !ok {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		// The level and difficulty are optional, an empty body plays the medium bot from a medium start word
+		var requestBody entities.CreateBotGameInput
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&requestBody); err != nil {
+				c.Status(fiber.StatusBadRequest)
+				return c.JSON(ErrorResponse(err))
+			}
+		}
+
+		if requestBody.Level != "" && !word.IsDifficulty(requestBody.Level) {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(ErrorResponse(fiber.NewError(fiber.StatusBadRequest, "invalid bot level")))
+		}
+		if requestBody.Difficulty != "" && !word.IsDifficulty(requestBody.Difficulty) {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(ErrorResponse(fiber.NewError(fiber.StatusBadRequest, "invalid difficulty")))
+		}
+
+		response, err := game.CreateBotGame(sessionCtx.ID, sessionCtx.Name, requestBody.Level, requestBody.Difficulty)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(ErrorResponse(err))
+		}
+
+		return c.JSON(response)
+	}
+}
+
+func CreatePracticeGame(game *gameService.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sessionCtx, ok := c.Locals("sessionContext").(entities.SessionContext)
+		if !ok {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		// An empty body practises against the clock from a medium start word
+		var requestBody entities.CreatePracticeGameInput
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&requestBody); err != nil {
+				c.Status(fiber.StatusBadRequest)
+				return c.JSON(ErrorResponse(err))
+			}
+		}
+
+		if requestBody.Difficulty != "" && !word.IsDifficulty(requestBody.Difficulty) {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(ErrorResponse(fiber.NewError(fiber.StatusBadRequest, "invalid difficulty")))
+		}
+
+		if requestBody.Mode != "" && requestBody.Mode != entities.PracticeModeClock && requestBody.Mode != entities.PracticeModeDictionary {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(ErrorResponse(fiber.NewError(fiber.StatusBadRequest, "invalid practice mode")))
+		}
+
+		response, err := game.CreatePracticeGame(sessionCtx.ID, sessionCtx.Name, requestBody.Mode, requestBody.Difficulty)
 		if err != nil {
 			c.Status(fiber.StatusInternalServerError)
 			return c.JSON(ErrorResponse(err))
@@ -110,3 +222,11 @@ func CancelMatchmaking(game *gameService.Service) fiber.Handler {
 		return c.JSON(fiber.Map{"status": "cancelled"})
 	}
 }
+
+// gameErrorStatus maps an error looking up a game to a status, anything but a missing game is a server error
+func gameErrorStatus(err error) int {
+	if errors.Is(err, gameService.ErrGameNotFound) {
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusInternalServerError
+}
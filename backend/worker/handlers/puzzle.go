@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+	puzzleService "github.com/simonPacker7/Delta/backend/worker/services/puzzle"
+)
+
+func GetDailyPuzzle(puzzle *puzzleService.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sessionCtx, ok := c.Locals("sessionContext").(entities.SessionContext)
+		if !ok {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		response, err := puzzle.GetDailyPuzzle(sessionCtx.ID)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(ErrorResponse(err))
+		}
+
+		return c.JSON(response)
+	}
+}
+
+func SubmitDailyPuzzle(puzzle *puzzleService.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sessionCtx, ok := c.Locals("sessionContext").(entities.SessionContext)
+		if !ok {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		var requestBody entities.SubmitPuzzleInput
+		if err := c.BodyParser(&requestBody); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(ErrorResponse(err))
+		}
+
+		response, err := puzzle.SubmitDailyPuzzle(sessionCtx.ID, requestBody.Date, requestBody.Words)
+		if err != nil {
+			c.Status(puzzleErrorStatus(err))
+			return c.JSON(ErrorResponse(err))
+		}
+
+		return c.JSON(response)
+	}
+}
+
+func GetPuzzleLeaderboard(puzzle *puzzleService.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		response, err := puzzle.GetLeaderboard(c.Query("date"), c.QueryInt("limit"))
+		if err != nil {
+			c.Status(puzzleErrorStatus(err))
+			return c.JSON(ErrorResponse(err))
+		}
+
+		return c.JSON(response)
+	}
+}
+
+// puzzleErrorStatus maps a puzzle service error to a status, anything not caused by the request is a server error
+func puzzleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, puzzleService.ErrInvalidDate), errors.Is(err, puzzleService.ErrInvalidSolution):
+		return fiber.StatusBadRequest
+	case errors.Is(err, puzzleService.ErrAlreadySolved):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
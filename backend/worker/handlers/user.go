@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,7 +11,10 @@ import (
 
 func GetUserProfile(users *userService.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		session := c.Locals("sessionContext").(entities.SessionContext)
+		session, ok := c.Locals("sessionContext").(entities.SessionContext)
+		if !ok {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
 
 		profile, err := users.GetUserProfile(session.Email)
 
@@ -22,3 +26,24 @@ func GetUserProfile(users *userService.Service) fiber.Handler {
 		return c.JSON(profile)
 	}
 }
+
+func GetGameHistory(users *userService.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		session, ok := c.Locals("sessionContext").(entities.SessionContext)
+		if !ok {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		history, err := users.GetGameHistory(session.ID, c.Query("cursor"), c.QueryInt("limit"))
+		if err != nil {
+			if errors.Is(err, userService.ErrInvalidCursor) {
+				c.Status(http.StatusBadRequest)
+			} else {
+				c.Status(http.StatusInternalServerError)
+			}
+			return c.JSON(ErrorResponse(err))
+		}
+
+		return c.JSON(history)
+	}
+}
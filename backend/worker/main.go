@@ -7,12 +7,13 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/simonPacker7/Delta/backend/shared/postgresclient"
 	"github.com/simonPacker7/Delta/backend/shared/redisclient"
+	"github.com/simonPacker7/Delta/backend/shared/word"
 	"github.com/simonPacker7/Delta/backend/worker/routes"
 	authService "github.com/simonPacker7/Delta/backend/worker/services/auth"
 	gameService "github.com/simonPacker7/Delta/backend/worker/services/game"
+	puzzleService "github.com/simonPacker7/Delta/backend/worker/services/puzzle"
 	sessionService "github.com/simonPacker7/Delta/backend/worker/services/session"
 	userService "github.com/simonPacker7/Delta/backend/worker/services/user"
-	wordService "github.com/simonPacker7/Delta/backend/worker/services/word"
 )
 
 func main() {
@@ -30,9 +31,9 @@ func main() {
 	dbUsername := os.Getenv("DATABASE_USERNAME")
 	dbPassword := os.Getenv("DATABASE_PASSWORD")
 
-	startWordsPath := os.Getenv("START_WORDS_PATH")
-	if startWordsPath == "" {
-		startWordsPath = "/app/assets/4-StartWords.json"
+	wordAssetsDir := os.Getenv("WORD_ASSETS_DIR")
+	if wordAssetsDir == "" {
+		wordAssetsDir = "/app/assets"
 	}
 
 	var redisConfig = redisclient.RedisConfig{
@@ -61,13 +62,15 @@ func main() {
 	auth := authService.NewService(pClient)
 	session := sessionService.NewService(rClient, &redisConfig)
 	users := userService.NewService(pClient)
-	words := wordService.NewService(startWordsPath)
-	game := gameService.NewService(rClient, words)
+	words := word.NewService(wordAssetsDir)
+	game := gameService.NewService(rClient, pClient, words)
+	puzzle := puzzleService.NewService(pClient, words)
 
 	// Create endpoints
 	routes.AuthRouter(app.Group("/api/auth"), auth, session)
 	routes.UserRouter(app.Group("/api/user"), users, session)
 	routes.GameRouter(app.Group("/api/game"), game, session)
+	routes.PuzzleRouter(app.Group("/api/puzzle"), puzzle, session)
 
 	app.Listen(":" + port)
 }
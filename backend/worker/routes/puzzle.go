@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/simonPacker7/Delta/backend/worker/handlers"
+	puzzleService "github.com/simonPacker7/Delta/backend/worker/services/puzzle"
+	sessionService "github.com/simonPacker7/Delta/backend/worker/services/session"
+)
+
+func PuzzleRouter(app fiber.Router, puzzle *puzzleService.Service, sess *sessionService.Service) {
+	app.Use(handlers.AuthRoute(sess))
+	app.Get("/daily", handlers.GetDailyPuzzle(puzzle))
+	app.Post("/daily", handlers.SubmitDailyPuzzle(puzzle))
+	app.Get("/daily/leaderboard", handlers.GetPuzzleLeaderboard(puzzle))
+}
@@ -10,4 +10,5 @@ import (
 func UserRouter(app fiber.Router, users *userService.Service, sess *sessionService.Service) {
 	app.Use(handlers.AuthRoute(sess))
 	app.Get("/profile", handlers.GetUserProfile(users))
+	app.Get("/games", handlers.GetGameHistory(users))
 }
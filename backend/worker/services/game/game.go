@@ -2,33 +2,54 @@ package gameService
 
 import (
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/postgresclient"
 	"github.com/simonPacker7/Delta/backend/shared/redisclient"
-	wordService "github.com/simonPacker7/Delta/backend/worker/services/word"
+	"github.com/simonPacker7/Delta/backend/shared/word"
 )
 
+// ErrGameNotFound is returned for a game that doesn't exist, or whose moves haven't been stored yet
+var ErrGameNotFound = errors.New("game not found")
+
 type Service struct {
-	redisClient *redisclient.RedisClient
-	wordService *wordService.Service
+	redisClient    *redisclient.RedisClient
+	postgresClient *postgresclient.PostgresClient
+	wordService    *word.Service
 }
 
-func NewService(r *redisclient.RedisClient, w *wordService.Service) *Service {
+func NewService(r *redisclient.RedisClient, p *postgresclient.PostgresClient, w *word.Service) *Service {
 	return &Service{
-		redisClient: r,
-		wordService: w,
+		redisClient:    r,
+		postgresClient: p,
+		wordService:    w,
 	}
 }
 
 // FindGame implements the matchmaking flow using atomic Redis operations:
 // 1. Atomically try to pop and join a waiting game from the queue
 // 2. If no valid game found, create a new game and add to queue
-func (s *Service) FindGame(playerID string, playerName string) (entities.FindGameResponse, error) {
-	startWord := s.wordService.GetRandomStartWord()
+func (s *Service) FindGame(playerID string, playerName string, difficulty entities.Difficulty) (entities.FindGameResponse, error) {
+	if difficulty == "" {
+		difficulty = entities.DifficultyMedium
+	}
+
+	seed := word.NewSeed()
+	startWord, err := s.wordService.PickStartWord(entities.DefaultWordLength, difficulty, seed)
+	if err != nil {
+		return entities.FindGameResponse{}, err
+	}
+
+	rating, err := s.postgresClient.GetUserRating(playerID)
+	if err != nil {
+		return entities.FindGameResponse{}, err
+	}
 
 	// Atomically try to pop and join a game
-	gameID, _, matched, err := s.redisClient.AtomicPopAndJoinGame(playerID, playerName, startWord)
+	gameID, _, matched, err := s.redisClient.AtomicPopAndJoinGame(playerID, playerName, rating, difficulty, startWord, seed)
 	if err != nil {
 		return entities.FindGameResponse{}, err
 	}
@@ -41,25 +62,33 @@ func (s *Service) FindGame(playerID string, playerName string) (entities.FindGam
 	}
 
 	// No valid game found - create a new one
-	return s.createNewGame(playerID, playerName)
+	return s.createNewGame(playerID, playerName, rating, difficulty)
 }
 
-func (s *Service) createNewGame(playerID string, playerName string) (entities.FindGameResponse, error) {
+func (s *Service) createNewGame(playerID string, playerName string, rating int, difficulty entities.Difficulty) (entities.FindGameResponse, error) {
 	gameID := redisclient.GenerateId()
 
 	game := entities.Game{
-		ID:             gameID,
-		Type:           entities.GameTypeOnline,
-		Status:         entities.GameStatusWaiting,
-		JoinCode:       "",
-		Player1ID:      playerID,
-		Player1Name:    playerName,
-		Player2ID:      "",
-		Player2Name:    "",
-		CurrentWord:    "",
-		CurrentTurnID:  "",
-		ConnectedCount: 0,
-		CreatedAt:      time.Now().UnixMilli(),
+		ID:                gameID,
+		Type:              entities.GameTypeOnline,
+		Status:            entities.GameStatusWaiting,
+		JoinCode:          "",
+		Player1ID:         playerID,
+		Player1Name:       playerName,
+		Player2ID:         "",
+		Player2Name:       "",
+		Player1Rating:     rating,
+		Rated:             true,
+		CurrentWord:       "",
+		CurrentTurnID:     "",
+		ConnectedCount:    0,
+		TimeBaseMs:        entities.DefaultTimeBaseMs,
+		TimeIncrementMs:   entities.DefaultTimeIncrementMs,
+		WordLength:        entities.DefaultWordLength,
+		StartWord:         entities.StartWordRandom,
+		CreatedAt:         time.Now().UnixMilli(),
+		DictionaryVersion: s.wordService.Version(),
+		Difficulty:        difficulty,
 	}
 
 	err := s.redisClient.CreateGame(game)
@@ -68,7 +97,7 @@ func (s *Service) createNewGame(playerID string, playerName string) (entities.Fi
 	}
 
 	// Add to matchmaking queue
-	err = s.redisClient.PushToMatchmakingQueue(gameID)
+	err = s.redisClient.PushToMatchmakingQueue(gameID, rating, game.CreatedAt)
 	if err != nil {
 		return entities.FindGameResponse{}, err
 	}
@@ -86,32 +115,147 @@ func (s *Service) GetGame(gameID string) (entities.Game, error) {
 		return entities.Game{}, err
 	}
 	if game.ID == "" {
-		return entities.Game{}, errors.New("game not found")
+		return entities.Game{}, ErrGameNotFound
 	}
 	return game, nil
 }
 
-// CreatePrivateGame creates a new private game with a join code
-func (s *Service) CreatePrivateGame(playerID string, playerName string) (entities.CreatePrivateGameResponse, error) {
+// GetGameMoves retrieves the move history of a completed game
+func (s *Service) GetGameMoves(gameID string) (entities.GameMovesResponse, error) {
+	if _, err := uuid.Parse(gameID); err != nil {
+		return entities.GameMovesResponse{}, ErrGameNotFound
+	}
+
+	moves, err := s.postgresClient.GetGameMoves(gameID)
+	if err != nil {
+		return entities.GameMovesResponse{}, err
+	}
+	if len(moves) == 0 {
+		return entities.GameMovesResponse{}, ErrGameNotFound
+	}
+
+	return entities.GameMovesResponse{
+		GameID: gameID,
+		Moves:  moves,
+	}, nil
+}
+
+// Bounds on the time control a private game creator can choose
+const (
+	minTimeBaseMs      int64 = 30 * 1000
+	maxTimeBaseMs      int64 = 30 * 60 * 1000
+	maxTimeIncrementMs int64 = 60 * 1000
+)
+
+// ValidateSettings checks the settings for a private game and fills in defaults for any left unset
+func (s *Service) ValidateSettings(settings *entities.GameSettings) error {
+	// Only fields left unset take the default, an increment chosen without a base time is kept
+	if settings.TimeBaseMs == 0 {
+		settings.TimeBaseMs = entities.DefaultTimeBaseMs
+		if settings.TimeIncrementMs == 0 {
+			settings.TimeIncrementMs = entities.DefaultTimeIncrementMs
+		}
+	}
+	if settings.TimeBaseMs < minTimeBaseMs || settings.TimeBaseMs > maxTimeBaseMs {
+		return errors.New("time control must be between 30 seconds and 30 minutes")
+	}
+	if settings.TimeIncrementMs < 0 || settings.TimeIncrementMs > maxTimeIncrementMs {
+		return errors.New("increment must be between 0 and 60 seconds")
+	}
+
+	if settings.WordLength == 0 {
+		settings.WordLength = entities.DefaultWordLength
+	}
+	if !s.wordService.SupportsWordLength(settings.WordLength) {
+		return errors.New("unsupported word length")
+	}
+
+	if settings.Difficulty == "" {
+		settings.Difficulty = entities.DifficultyMedium
+	}
+	if !word.IsDifficulty(settings.Difficulty) {
+		return errors.New("invalid difficulty")
+	}
+	// The random start word is picked when the game fills, so make sure there is one to pick
+	if _, err := s.wordService.PickStartWord(settings.WordLength, settings.Difficulty, 0); err != nil {
+		return err
+	}
+
+	if settings.Teams {
+		if settings.MaxPlayers != 0 && settings.MaxPlayers != 2*entities.TeamSize {
+			return errors.New("team games are for 4 players")
+		}
+		settings.MaxPlayers = 2 * entities.TeamSize
+	}
+	if settings.MaxPlayers == 0 {
+		settings.MaxPlayers = entities.MinPlayers
+	}
+	if settings.MaxPlayers < entities.MinPlayers || settings.MaxPlayers > entities.MaxPlayers {
+		return errors.New("players must be between 2 and 6")
+	}
+	if settings.Race && settings.MaxPlayers != 2 {
+		return errors.New("race games are for 2 players")
+	}
+
+	settings.StartWord = strings.ToUpper(strings.TrimSpace(settings.StartWord))
+	if settings.StartWord == "" || settings.StartWord == strings.ToUpper(entities.StartWordRandom) {
+		settings.StartWord = entities.StartWordRandom
+		return nil
+	}
+	if len(settings.StartWord) != settings.WordLength || !s.wordService.IsStartWord(settings.StartWord) {
+		return errors.New("invalid start word")
+	}
+
+	return nil
+}
+
+// CreatePrivateGame creates a new private game with a join code using validated settings.
+// With more than two players the game is a lobby that the creator starts from game-service
+func (s *Service) CreatePrivateGame(playerID string, playerName string, settings entities.GameSettings) (entities.CreatePrivateGameResponse, error) {
 	gameID := redisclient.GenerateId()
 	joinCode := redisclient.GenerateGameCode()
 
+	rating, err := s.postgresClient.GetUserRating(playerID)
+	if err != nil {
+		return entities.CreatePrivateGameResponse{}, err
+	}
+
 	game := entities.Game{
-		ID:             gameID,
-		Type:           entities.GameTypePrivate,
-		Status:         entities.GameStatusWaiting,
-		JoinCode:       joinCode,
-		Player1ID:      playerID,
-		Player1Name:    playerName,
-		Player2ID:      "",
-		Player2Name:    "",
-		CurrentWord:    "",
-		CurrentTurnID:  "",
-		ConnectedCount: 0,
-		CreatedAt:      time.Now().UnixMilli(),
+		ID:                gameID,
+		Type:              entities.GameTypePrivate,
+		Status:            entities.GameStatusWaiting,
+		JoinCode:          joinCode,
+		Player1ID:         playerID,
+		Player1Name:       playerName,
+		Player2ID:         "",
+		Player2Name:       "",
+		Player1Rating:     rating,
+		Rated:             false, // Private games never affect ratings
+		CurrentWord:       "",
+		CurrentTurnID:     "",
+		ConnectedCount:    0,
+		TimeBaseMs:        settings.TimeBaseMs,
+		TimeIncrementMs:   settings.TimeIncrementMs,
+		WordLength:        settings.WordLength,
+		AllowRepeats:      settings.AllowRepeats,
+		StartWord:         settings.StartWord,
+		CreatedAt:         time.Now().UnixMilli(),
+		DictionaryVersion: s.wordService.Version(),
+		Difficulty:        settings.Difficulty,
+	}
+	if settings.MaxPlayers > 2 {
+		game.MaxPlayers = settings.MaxPlayers
+		game.Players = []entities.GamePlayer{{ID: playerID, Name: playerName}}
+	}
+	if settings.Teams {
+		game.Type = entities.GameTypeTeam
+		game.Players[0].Team = 1
+	}
+	if settings.Race {
+		game.Type = entities.GameTypeRace
 	}
 
-	err := s.redisClient.CreateGame(game)
+	err = s.redisClient.CreateGame(game)
 	if err != nil {
 		return entities.CreatePrivateGameResponse{}, err
 	}
@@ -131,10 +275,48 @@ func (s *Service) CreatePrivateGame(playerID string, playerName string) (entitie
 // JoinPrivateGame atomically joins a private game using a join code
 // Uses Lua script to prevent race conditions when two players try to join simultaneously
 func (s *Service) JoinPrivateGame(playerID string, playerName string, joinCode string) (entities.JoinPrivateGameResponse, error) {
-	startWord := s.wordService.GetRandomStartWord()
+	// Look up the game's word length and difficulty so the random start word matches them
+	gameID, err := s.redisClient.GetGameIDByCode(joinCode)
+	if err != nil {
+		return entities.JoinPrivateGameResponse{}, errors.New("invalid join code")
+	}
+	game, err := s.redisClient.GetGame(gameID)
+	if err != nil {
+		return entities.JoinPrivateGameResponse{}, err
+	}
+	wordLength := game.WordLength
+	if wordLength == 0 {
+		wordLength = entities.DefaultWordLength
+	}
+	if !s.wordService.SupportsWordLength(wordLength) {
+		return entities.JoinPrivateGameResponse{}, errors.New("unsupported word length")
+	}
+	difficulty := game.Difficulty
+	if difficulty == "" {
+		difficulty = entities.DifficultyMedium
+	}
+	seed := word.NewSeed()
+	startWord, err := s.wordService.PickStartWord(wordLength, difficulty, seed)
+	if err != nil {
+		return entities.JoinPrivateGameResponse{}, err
+	}
+
+	// A race needs its target picked for the actual start word, which may have been chosen by the creator
+	targetWord, par := "", 0
+	if game.Type == entities.GameTypeRace {
+		if game.StartWord != "" && game.StartWord != entities.StartWordRandom {
+			startWord = game.StartWord
+		}
+		targetWord, par = s.wordService.PickTarget(startWord, difficulty, seed)
+	}
+
+	rating, err := s.postgresClient.GetUserRating(playerID)
+	if err != nil {
+		return entities.JoinPrivateGameResponse{}, err
+	}
 
 	// Atomically validate and join the game
-	gameID, _, err := s.redisClient.AtomicJoinPrivateGame(joinCode, playerID, playerName, startWord)
+	gameID, _, err = s.redisClient.AtomicJoinPrivateGame(joinCode, playerID, playerName, rating, startWord, seed, targetWord, par)
 	if err != nil {
 		// Convert atomic operation errors to user-friendly messages
 		if atomicErr, ok := err.(*redisclient.AtomicOperationError); ok {
@@ -145,19 +327,140 @@ func (s *Service) JoinPrivateGame(playerID string, playerName string, joinCode s
 				return entities.JoinPrivateGameResponse{}, errors.New("game is no longer available")
 			case "cannot_join_own_game":
 				return entities.JoinPrivateGameResponse{}, errors.New("cannot join your own game")
+			case "game_full":
+				return entities.JoinPrivateGameResponse{}, errors.New("game is full")
 			}
 		}
 		return entities.JoinPrivateGameResponse{}, err
 	}
 
+	// Lobby players wait for the host to start the game
+	if game.MaxPlayers > 2 {
+		return entities.JoinPrivateGameResponse{
+			Status: "waiting",
+			GameID: gameID,
+		}, nil
+	}
+
 	return entities.JoinPrivateGameResponse{
 		Status: "matched",
 		GameID: gameID,
 	}, nil
 }
 
+// CreateBotGame starts a game against a bot of the given level from a start word of the given difficulty.
+// The bot's strength doesn't change the start word. The bot is played by the arbiter and the game is never rated
+func (s *Service) CreateBotGame(playerID string, playerName string, level entities.Difficulty, difficulty entities.Difficulty) (entities.FindGameResponse, error) {
+	if level == "" {
+		level = entities.DifficultyMedium
+	}
+	if difficulty == "" {
+		difficulty = entities.DifficultyMedium
+	}
+	bot, ok := entities.GetBotByLevel(level)
+	if !ok {
+		return entities.FindGameResponse{}, errors.New("invalid bot level")
+	}
+
+	seed := word.NewSeed()
+	startWord, err := s.wordService.PickStartWord(entities.DefaultWordLength, difficulty, seed)
+	if err != nil {
+		return entities.FindGameResponse{}, err
+	}
+	gameID := redisclient.GenerateId()
+
+	game := entities.Game{
+		ID:                gameID,
+		Type:              entities.GameTypeBot,
+		Status:            entities.GameStatusReady,
+		JoinCode:          "",
+		Player1ID:         playerID,
+		Player1Name:       playerName,
+		Player2ID:         bot.ID,
+		Player2Name:       bot.Name,
+		Rated:             false,
+		CurrentWord:       startWord,
+		CurrentTurnID:     playerID,
+		ConnectedCount:    1, // the bot is always connected
+		TimeBaseMs:        entities.DefaultTimeBaseMs,
+		TimeIncrementMs:   entities.DefaultTimeIncrementMs,
+		WordLength:        entities.DefaultWordLength,
+		StartWord:         entities.StartWordRandom,
+		CreatedAt:         time.Now().UnixMilli(),
+		DictionaryVersion: s.wordService.Version(),
+		Difficulty:        difficulty,
+		StartWordSeed:     seed,
+		BotLevel:          level,
+	}
+
+	err = s.redisClient.CreateReadyGame(game)
+	if err != nil {
+		return entities.FindGameResponse{}, err
+	}
+
+	return entities.FindGameResponse{
+		Status: "matched",
+		GameID: gameID,
+	}, nil
+}
+
+// CreatePracticeGame starts a single player practice game against the clock or the dictionary.
+// It skips matchmaking and starts as soon as the player connects to game-service
+func (s *Service) CreatePracticeGame(playerID string, playerName string, mode entities.PracticeMode, difficulty entities.Difficulty) (entities.FindGameResponse, error) {
+	if mode == "" {
+		mode = entities.PracticeModeClock
+	}
+	if mode != entities.PracticeModeClock && mode != entities.PracticeModeDictionary {
+		return entities.FindGameResponse{}, errors.New("invalid practice mode")
+	}
+	if difficulty == "" {
+		difficulty = entities.DifficultyMedium
+	}
+
+	seed := word.NewSeed()
+	startWord, err := s.wordService.PickStartWord(entities.DefaultWordLength, difficulty, seed)
+	if err != nil {
+		return entities.FindGameResponse{}, err
+	}
+	gameID := redisclient.GenerateId()
+
+	game := entities.Game{
+		ID:                gameID,
+		Type:              entities.GameTypePractice,
+		Status:            entities.GameStatusReady,
+		JoinCode:          "",
+		Player1ID:         playerID,
+		Player1Name:       playerName,
+		Player2ID:         "",
+		Player2Name:       "",
+		Rated:             false,
+		CurrentWord:       startWord,
+		CurrentTurnID:     playerID,
+		ConnectedCount:    0,
+		TimeBaseMs:        entities.DefaultTimeBaseMs,
+		TimeIncrementMs:   entities.DefaultTimeIncrementMs,
+		WordLength:        entities.DefaultWordLength,
+		StartWord:         entities.StartWordRandom,
+		CreatedAt:         time.Now().UnixMilli(),
+		DictionaryVersion: s.wordService.Version(),
+		Difficulty:        difficulty,
+		StartWordSeed:     seed,
+		PracticeMode:      mode,
+	}
+
+	err = s.redisClient.CreateReadyGame(game)
+	if err != nil {
+		return entities.FindGameResponse{}, err
+	}
+
+	return entities.FindGameResponse{
+		Status: "matched",
+		GameID: gameID,
+	}, nil
+}
+
 // CancelMatchmaking removes a waiting game from matchmaking
-// Only the game creator (player1) can cancel
+// Only the game creator (player1) can cancel, anyone else in a lobby gives up their seat instead
 func (s *Service) CancelMatchmaking(gameID string, playerID string) error {
 	// First get the game to check its join code (for private games)
 	game, err := s.redisClient.GetGame(gameID)
@@ -165,7 +468,11 @@ func (s *Service) CancelMatchmaking(gameID string, playerID string) error {
 		return err
 	}
 	if game.ID == "" {
-		return errors.New("game not found")
+		return ErrGameNotFound
+	}
+
+	if game.MaxPlayers > 2 && game.Player1ID != playerID {
+		return s.redisClient.AtomicLeaveLobby(gameID, playerID)
 	}
 
 	// AtomicCancelMatchmaking handles all validation (status, authorization)
@@ -0,0 +1,176 @@
+package puzzleService
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/postgresclient"
+	"github.com/simonPacker7/Delta/backend/shared/word"
+)
+
+// resultStore is the part of the Postgres client the puzzle service uses to record results
+type resultStore interface {
+	SavePuzzleResult(date string, playerID string, words []string) (bool, error)
+	GetPuzzleResult(date string, playerID string) (*entities.PuzzleResult, error)
+	GetPuzzleLeaderboard(date string, limit int) ([]entities.PuzzleLeaderboardEntry, error)
+}
+
+type Service struct {
+	postgresClient resultStore
+	wordService    *word.Service
+}
+
+func NewService(p *postgresclient.PostgresClient, w *word.Service) *Service {
+	return &Service{
+		postgresClient: p,
+		wordService:    w,
+	}
+}
+
+// Every daily puzzle is a medium difficulty race from start to target with the default word length
+const puzzleDifficulty = entities.DifficultyMedium
+
+// Errors caused by the request rather than the server, so the handler can tell them apart from database failures
+var (
+	ErrInvalidDate     = errors.New("invalid date")
+	ErrInvalidSolution = errors.New("invalid solution")
+	ErrAlreadySolved   = errors.New("puzzle already solved")
+)
+
+const (
+	defaultLeaderboardSize = 20
+	maxLeaderboardSize     = 100
+)
+
+// puzzleFor derives the puzzle for a day. The seed comes from the date, so every instance
+// with the same dictionary version gives every player the same puzzle
+func (s *Service) puzzleFor(date string) (entities.DailyPuzzle, error) {
+	hash := fnv.New64a()
+	hash.Write([]byte(date))
+	seed := int64(hash.Sum64() >> 1)
+
+	startWord, err := s.wordService.PickStartWord(entities.DefaultWordLength, puzzleDifficulty, seed)
+	if err != nil {
+		return entities.DailyPuzzle{}, err
+	}
+	targetWord, par := s.wordService.PickTarget(startWord, puzzleDifficulty, seed)
+
+	return entities.DailyPuzzle{
+		Date:       date,
+		StartWord:  startWord,
+		TargetWord: targetWord,
+		Par:        par,
+	}, nil
+}
+
+func today() string {
+	return time.Now().UTC().Format(entities.PuzzleDateFormat)
+}
+
+func yesterday() string {
+	return time.Now().UTC().AddDate(0, 0, -1).Format(entities.PuzzleDateFormat)
+}
+
+// GetDailyPuzzle returns today's puzzle, with the player's result if they have already solved it
+func (s *Service) GetDailyPuzzle(playerID string) (entities.DailyPuzzle, error) {
+	puzzle, err := s.puzzleFor(today())
+	if err != nil {
+		return entities.DailyPuzzle{}, err
+	}
+
+	result, err := s.postgresClient.GetPuzzleResult(puzzle.Date, playerID)
+	if err != nil {
+		return entities.DailyPuzzle{}, err
+	}
+	if result != nil {
+		result.Par = puzzle.Par
+		puzzle.Result = result
+	}
+
+	return puzzle, nil
+}
+
+// SubmitDailyPuzzle checks a solution to the puzzle for date against the word map and records it.
+// An empty date means today. Yesterday's puzzle is still accepted, so a solution started before
+// midnight UTC is checked against the puzzle the player was shown. Only a player's first solution each day counts
+func (s *Service) SubmitDailyPuzzle(playerID string, date string, words []string) (entities.PuzzleResult, error) {
+	if date == "" {
+		date = today()
+	}
+	if date != today() && date != yesterday() {
+		return entities.PuzzleResult{}, ErrInvalidDate
+	}
+	puzzle, err := s.puzzleFor(date)
+	if err != nil {
+		return entities.PuzzleResult{}, err
+	}
+
+	solution := make([]string, len(words))
+	for i, w := range words {
+		solution[i] = strings.ToUpper(strings.TrimSpace(w))
+	}
+
+	if len(solution) < 2 || solution[0] != puzzle.StartWord || solution[len(solution)-1] != puzzle.TargetWord {
+		return entities.PuzzleResult{}, fmt.Errorf("%w: it must go from the start word to the target word", ErrInvalidSolution)
+	}
+	for i := 1; i < len(solution); i++ {
+		if !s.wordService.IsValidMove(entities.DefaultWordLength, solution[i-1], solution[i]) {
+			return entities.PuzzleResult{}, fmt.Errorf("%w: invalid move from %s to %s", ErrInvalidSolution, solution[i-1], solution[i])
+		}
+	}
+
+	saved, err := s.postgresClient.SavePuzzleResult(puzzle.Date, playerID, solution)
+	if err != nil {
+		return entities.PuzzleResult{}, err
+	}
+	if !saved {
+		return entities.PuzzleResult{}, ErrAlreadySolved
+	}
+
+	result, err := s.postgresClient.GetPuzzleResult(puzzle.Date, playerID)
+	if err != nil {
+		return entities.PuzzleResult{}, err
+	}
+	result.Par = puzzle.Par
+
+	return *result, nil
+}
+
+// GetLeaderboard returns the best results for the puzzle on date, today's if date is empty.
+// Future puzzles have no leaderboard, so they can't be looked up early
+func (s *Service) GetLeaderboard(date string, limit int) (entities.PuzzleLeaderboardResponse, error) {
+	if date == "" {
+		date = today()
+	}
+	day, err := time.Parse(entities.PuzzleDateFormat, date)
+	if err != nil || date > today() {
+		return entities.PuzzleLeaderboardResponse{}, ErrInvalidDate
+	}
+	date = day.Format(entities.PuzzleDateFormat)
+
+	if limit <= 0 {
+		limit = defaultLeaderboardSize
+	}
+	if limit > maxLeaderboardSize {
+		limit = maxLeaderboardSize
+	}
+
+	puzzle, err := s.puzzleFor(date)
+	if err != nil {
+		return entities.PuzzleLeaderboardResponse{}, err
+	}
+	entries, err := s.postgresClient.GetPuzzleLeaderboard(date, limit)
+	if err != nil {
+		return entities.PuzzleLeaderboardResponse{}, err
+	}
+
+	return entities.PuzzleLeaderboardResponse{
+		Date:    date,
+		Par:     puzzle.Par,
+		Entries: entries,
+	}, nil
+}
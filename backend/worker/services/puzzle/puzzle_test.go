@@ -0,0 +1,99 @@
+package puzzleService
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/simonPacker7/Delta/backend/shared/entities"
+	"github.com/simonPacker7/Delta/backend/shared/word"
+)
+
+// memoryStore keeps puzzle results in memory in place of Postgres, saving a player's first solution each day
+type memoryStore struct {
+	results map[string]*entities.PuzzleResult
+}
+
+func (m *memoryStore) SavePuzzleResult(date string, playerID string, words []string) (bool, error) {
+	if _, ok := m.results[date+playerID]; ok {
+		return false, nil
+	}
+	m.results[date+playerID] = &entities.PuzzleResult{Date: date, MoveCount: len(words) - 1, Rank: 1}
+	return true, nil
+}
+
+func (m *memoryStore) GetPuzzleResult(date string, playerID string) (*entities.PuzzleResult, error) {
+	result, ok := m.results[date+playerID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *result
+	return &copied, nil
+}
+
+func (m *memoryStore) GetPuzzleLeaderboard(date string, limit int) ([]entities.PuzzleLeaderboardEntry, error) {
+	return []entities.PuzzleLeaderboardEntry{}, nil
+}
+
+// Each start word in the test dictionary is two moves from its puzzle target, through a word next to both
+func TestSubmitDailyPuzzleValidatesSolution(t *testing.T) {
+	s := &Service{
+		postgresClient: &memoryStore{results: make(map[string]*entities.PuzzleResult)},
+		wordService:    word.NewTestService(),
+	}
+
+	puzzle, err := s.puzzleFor(today())
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution := solve(t, s, puzzle)
+
+	if _, err := s.SubmitDailyPuzzle("player-1", "", []string{solution[1], puzzle.TargetWord}); !errors.Is(err, ErrInvalidSolution) {
+		t.Fatalf("expected a solution not from the start word to be rejected, got %v", err)
+	}
+	if _, err := s.SubmitDailyPuzzle("player-1", "", solution[:2]); !errors.Is(err, ErrInvalidSolution) {
+		t.Fatalf("expected a solution not ending on the target word to be rejected, got %v", err)
+	}
+	if _, err := s.SubmitDailyPuzzle("player-1", "", []string{puzzle.StartWord, puzzle.TargetWord}); !errors.Is(err, ErrInvalidSolution) {
+		t.Fatalf("expected a step more than one letter apart to be rejected, got %v", err)
+	}
+	if _, err := s.SubmitDailyPuzzle("player-1", "2000-01-01", solution); !errors.Is(err, ErrInvalidDate) {
+		t.Fatalf("expected an old puzzle to be rejected, got %v", err)
+	}
+
+	result, err := s.SubmitDailyPuzzle("player-1", "", []string{"  " + solution[0], solution[1], solution[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.MoveCount != 2 || result.Par != 2 {
+		t.Fatalf("expected a 2 move result at par 2, got %+v", result)
+	}
+	if _, err := s.SubmitDailyPuzzle("player-1", today(), solution); !errors.Is(err, ErrAlreadySolved) {
+		t.Fatalf("expected a second solution to be rejected, got %v", err)
+	}
+
+	// A solution to yesterday's puzzle sent after midnight still counts, for yesterday
+	previous, err := s.puzzleFor(yesterday())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = s.SubmitDailyPuzzle("player-1", yesterday(), solve(t, s, previous))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Date != previous.Date {
+		t.Fatalf("expected the result to be recorded for %s, got %+v", previous.Date, result)
+	}
+}
+
+// solve returns the two move solution to a puzzle from the test dictionary
+func solve(t *testing.T, s *Service, puzzle entities.DailyPuzzle) []string {
+	t.Helper()
+
+	for _, next := range s.wordService.Moves(puzzle.StartWord) {
+		if puzzle.Par == 2 && s.wordService.IsValidMove(entities.DefaultWordLength, next, puzzle.TargetWord) {
+			return []string{puzzle.StartWord, next, puzzle.TargetWord}
+		}
+	}
+	t.Fatalf("expected a puzzle solved in 2 moves, got %+v", puzzle)
+	return nil
+}
@@ -1,6 +1,9 @@
 package userService
 
 import (
+	"errors"
+
+	"github.com/google/uuid"
 	"github.com/simonPacker7/Delta/backend/shared/entities"
 	"github.com/simonPacker7/Delta/backend/shared/postgresclient"
 )
@@ -18,3 +21,45 @@ func NewService(p *postgresclient.PostgresClient) *Service {
 func (s *Service) GetUserProfile(email string) (entities.UserProfile, error) {
 	return s.postgresService.GetUserProfile(email)
 }
+
+// ErrInvalidCursor is returned for a history cursor that isn't a game ID
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	defaultHistoryPageSize = 20
+	maxHistoryPageSize     = 50
+)
+
+// GetGameHistory returns a page of the player's completed games, newest first.
+// Pass the NextCursor from the previous page to continue, or an empty cursor for the first page
+func (s *Service) GetGameHistory(playerID string, cursor string, limit int) (entities.GameHistoryResponse, error) {
+	if limit <= 0 {
+		limit = defaultHistoryPageSize
+	}
+	if limit > maxHistoryPageSize {
+		limit = maxHistoryPageSize
+	}
+
+	if cursor != "" {
+		if _, err := uuid.Parse(cursor); err != nil {
+			return entities.GameHistoryResponse{}, ErrInvalidCursor
+		}
+	}
+
+	// Fetch one extra game to know whether another page exists
+	games, err := s.postgresService.GetGameHistory(playerID, cursor, limit+1)
+	if err != nil {
+		return entities.GameHistoryResponse{}, err
+	}
+
+	nextCursor := ""
+	if len(games) > limit {
+		games = games[:limit]
+		nextCursor = games[limit-1].GameID
+	}
+
+	return entities.GameHistoryResponse{
+		Games:      games,
+		NextCursor: nextCursor,
+	}, nil
+}
@@ -0,0 +1,21 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1 runInTransaction:false
+
+alter type win_reasons add value if not exists 'timeout'
+go
+
+alter table games drop column moves
+go
+
+create table game_moves (
+    game_id uuid references games(id) not null ,
+    move_number integer not null , -- 0 is the start word
+    player_id uuid references users(id) , -- null for the start word
+    word varchar(16) not null ,
+    played_at timestamp with time zone ,
+    primary key (game_id, move_number)
+)
+go
+
+create index idx_game_moves_player_id on game_moves(player_id)
+go
@@ -0,0 +1,17 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1
+
+alter table users add column rating integer not null default 1200
+go
+
+alter table users add column rated_games integer not null default 0
+go
+
+alter table games add column rated boolean not null default false
+go
+
+alter table games add column player_one_rating_delta integer not null default 0
+go
+
+alter table games add column player_two_rating_delta integer not null default 0
+go
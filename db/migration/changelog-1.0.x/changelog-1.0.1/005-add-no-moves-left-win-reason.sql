@@ -0,0 +1,5 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1 runInTransaction:false
+
+alter type win_reasons add value if not exists 'no_moves_left'
+go
@@ -0,0 +1,13 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1 runInTransaction:false
+
+alter type game_types add value if not exists 'bot'
+go
+
+-- Bots can't log in, their password hash never matches
+insert into users (id, username, email, hashed_password, created_at) values
+    ('00000000-0000-0000-0000-0000000b0001', 'Delta Bot (Easy)', 'easy@bot.delta.local', '!', now()),
+    ('00000000-0000-0000-0000-0000000b0002', 'Delta Bot (Medium)', 'medium@bot.delta.local', '!', now()),
+    ('00000000-0000-0000-0000-0000000b0003', 'Delta Bot (Hard)', 'hard@bot.delta.local', '!', now())
+on conflict (id) do nothing
+go
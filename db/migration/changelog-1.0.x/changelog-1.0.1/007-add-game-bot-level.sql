@@ -0,0 +1,6 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1
+
+-- Set for games against a bot, which never change ratings
+alter table games add column bot_level varchar(16)
+go
@@ -0,0 +1,12 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1 runInTransaction:false
+
+alter type game_types add value if not exists 'team'
+go
+
+-- Team games are won by a team, each player is recorded with their team
+alter table games add column winning_team smallint
+go
+
+alter table game_players add column team smallint
+go
@@ -0,0 +1,12 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1 runInTransaction:false
+
+alter type game_types add value if not exists 'race'
+go
+
+alter type win_reasons add value if not exists 'target_reached'
+go
+
+-- Fewest moves from the start word to the target word in a race
+alter table games add column par integer
+go
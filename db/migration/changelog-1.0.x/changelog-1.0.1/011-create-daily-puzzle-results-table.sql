@@ -0,0 +1,16 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1
+
+-- One solution per player per daily puzzle, ranked by moves then by who solved it first
+create table daily_puzzle_results (
+    puzzle_date date not null ,
+    player_id uuid not null references users(id) ,
+    move_count integer not null ,
+    words jsonb not null , -- the solution, from the start word to the target word
+    submitted_at timestamp with time zone not null ,
+    primary key (puzzle_date, player_id)
+)
+go
+
+create index idx_daily_puzzle_results_rank on daily_puzzle_results(puzzle_date, move_count, submitted_at)
+go
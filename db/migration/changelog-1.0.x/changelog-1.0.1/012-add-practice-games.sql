@@ -0,0 +1,9 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1 runInTransaction:false
+
+alter type game_types add value if not exists 'practice'
+go
+
+-- Final score of a single player practice game
+alter table games add column score integer
+go
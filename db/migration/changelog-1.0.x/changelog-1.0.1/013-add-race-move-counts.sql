@@ -0,0 +1,9 @@
+--liquibase formatted sql
+--changeset Simon.Packer:1
+
+-- Moves each player took in a race, to compare against par
+alter table games add column player_one_move_count integer
+go
+
+alter table games add column player_two_move_count integer
+go
@@ -139,6 +139,13 @@ export const useGameStore = defineStore("game", () => {
                 console.log("Joined game:", update.gameId);
                 break;
 
+            case "matchmaking_matched":
+                // Matched with a player who was already waiting, their game replaces ours
+                gameId.value = payload.gameId;
+                gameStatus.value = "ready";
+                SubscribeToGame(payload.gameId);
+                break;
+
             case "game_started":
                 // Both players connected, game is starting
                 gameStatus.value = "active";